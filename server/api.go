@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -54,6 +55,53 @@ func (p *Plugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Req
 	if route == "/command/rollback" {
 		p.handleRollbackBuildSelectResponse(w, r)
 	}
+	// When user presses undo on the post of a successful rollback
+	if route == "/command/rollback-undo" {
+		p.handleRollbackUndoResponse(w, r)
+	}
+
+	// When user reveals the URL of a build hook or deletes it
+	if route == "/command/buildhooks" {
+		p.handleBuildHooksCommandResponse(w, r)
+	}
+	// When user confirms or cancels deleting a build hook
+	if route == "/command/buildhooks-delete" {
+		p.handleBuildHookDeleteResponse(w, r)
+	}
+
+	// When user enables, disables or deletes a notification hook
+	if route == "/command/hooks" {
+		p.handleHooksCommandResponse(w, r)
+	}
+	// When user confirms or cancels deleting a notification hook
+	if route == "/command/hooks-delete" {
+		p.handleNotificationHookDeleteResponse(w, r)
+	}
+
+	// When user submits the dialog for creating a site
+	if route == "/dialog/site-create" {
+		p.handleSiteCreateDialogSubmission(w, r)
+	}
+
+	// When user submits the dialog for deleting a site
+	if route == "/dialog/site-delete" {
+		p.handleSiteDeleteDialogSubmission(w, r)
+	}
+
+	// When user wants to edit settings from the site information post
+	if route == "/command/site-settings" {
+		p.handleSiteSettingsEditResponse(w, r)
+	}
+
+	// When user submits the dialog for editing settings of a site
+	if route == "/dialog/site-settings" {
+		p.handleSiteSettingsDialogSubmission(w, r)
+	}
+
+	// When user selects a building deploy to cancel, either from cancel command or from a build notification
+	if route == "/command/cancel" {
+		p.handleCancelCommandResponse(w, r)
+	}
 
 	// When user selects a site for subscribing notifications
 	if route == "/command/subscribe" {
@@ -63,6 +111,45 @@ func (p *Plugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Req
 	if route == "/command/site" {
 		p.handleSiteCommandResponse(w, r)
 	}
+
+	// When user reveals masked environment variables
+	if route == "/command/env-reveal" {
+		p.handleEnvRevealResponse(w, r)
+	}
+
+	// When user provisions a certificate or forces HTTPS on a site
+	if route == "/command/ssl" {
+		p.handleSSLCommandResponse(w, r)
+	}
+
+	// When user marks a form submission as spam or ham or deletes it
+	if route == "/command/submissions" {
+		p.handleSubmissionsCommandResponse(w, r)
+	}
+	// When user confirms or cancels deleting a form submission
+	if route == "/command/submissions-delete" {
+		p.handleSubmissionDeleteResponse(w, r)
+	}
+
+	// When user confirms or cancels deleting a DNS record
+	if route == "/command/dns-delete" {
+		p.handleDNSRecordDeleteResponse(w, r)
+	}
+
+	// When user moves across pages of deploy history
+	if route == "/command/deploys" {
+		p.handleDeploysCommandResponse(w, r)
+	}
+
+	// When user moves across pages of audit log of an account
+	if route == "/command/audit" {
+		p.handleAuditCommandResponse(w, r)
+	}
+
+	// When user selects a site to view its deploy status
+	if route == "/command/status" {
+		p.handleStatusCommandResponse(w, r)
+	}
 }
 
 func (p *Plugin) getOAuthConfig() *oauth2.Config {
@@ -321,10 +408,19 @@ func (p *Plugin) handleDeployCommandResponse(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Comprises of id name branch
-	selectedOption := intergrationResponseFromCommand.Context["selected_option"].(string)
+	selectedOption, ok := intergrationResponseFromCommand.Context["selected_option"].(string)
 
 	// Get the information from Body which contain the interactive Message Attachment we sent from /disconnect command
 	selectedOptionsValue := strings.Fields(selectedOption)
+	if !ok || len(selectedOptionsValue) < 3 {
+		p.API.SendEphemeralPost(userID, &model.Post{
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(
+				":exclamation: One of more values while selecting from dropdown were empty"),
+		})
+		return
+	}
 
 	// Extract the selected site information
 	siteID := selectedOptionsValue[0]
@@ -346,7 +442,7 @@ func (p *Plugin) handleDeployCommandResponse(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Check if any is empty
-	if len(selectedOptionsValue) == 0 || len(siteID) == 0 || len(siteName) == 0 || len(siteBranch) == 0 {
+	if len(siteID) == 0 || len(siteName) == 0 || len(siteBranch) == 0 {
 		p.API.SendEphemeralPost(userID, &model.Post{
 			UserId:    p.BotUserID,
 			ChannelId: channelID,
@@ -364,6 +460,12 @@ func (p *Plugin) handleDeployCommandResponse(w http.ResponseWriter, r *http.Requ
 		Message:   fmt.Sprintf(":loudspeaker: Mattermost Netlify Bot is preparing to deploy **%v** branch of **%v** site.", siteBranch, siteName),
 	})
 
+	// Warn the user if production publishing of the site is locked
+	deployLockWarning := p.getDeployLockWarning(userID, siteID, siteName)
+	if len(deployLockWarning) != 0 {
+		p.sendMessageFromBot(channelID, userID, true, deployLockWarning)
+	}
+
 	// Check if build hook from Mattermost already exist
 	listBuildHooksParams := &netlifyPlumbingModels.ListSiteBuildHooksParams{
 		SiteID:  siteID,
@@ -475,7 +577,7 @@ func (p *Plugin) handleRollbackCommandResponse(w http.ResponseWriter, r *http.Re
 	userID := intergrationResponseFromCommand.UserId
 	channelID := intergrationResponseFromCommand.ChannelId
 
-	actionSecretPassed := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
 	actionSecret := p.getConfiguration().EncryptionKey
 
 	// If action was not initiated from within MM
@@ -501,16 +603,24 @@ func (p *Plugin) handleRollbackCommandResponse(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	selectedOption := intergrationResponseFromCommand.Context["selected_option"].(string)
-	// Get the information from Body which contain the interactive Message Attachment we sent from /disconnect command
-	selectedOptionsValue := strings.Fields(selectedOption)
+	var siteID, siteName string
 
-	// Extract the selected site information
-	siteID := selectedOptionsValue[0]
-	siteName := selectedOptionsValue[1]
+	// Selecting a site from rollback command passes "id name" whereas
+	// older and newer buttons pass them as separate context values along with the page
+	selectedOption, isSelectedFromDropdown := intergrationResponseFromCommand.Context["selected_option"].(string)
+	if isSelectedFromDropdown == true {
+		selectedOptionsValue := strings.Fields(selectedOption)
+		if len(selectedOptionsValue) == 2 {
+			siteID = selectedOptionsValue[0]
+			siteName = selectedOptionsValue[1]
+		}
+	} else {
+		siteID, _ = intergrationResponseFromCommand.Context["siteID"].(string)
+		siteName, _ = intergrationResponseFromCommand.Context["siteName"].(string)
+	}
 
 	// Check if any is empty
-	if len(selectedOptionsValue) == 0 || len(siteID) == 0 || len(siteName) == 0 {
+	if len(siteID) == 0 || len(siteName) == 0 {
 		p.API.SendEphemeralPost(userID, &model.Post{
 			UserId:    p.BotUserID,
 			ChannelId: channelID,
@@ -520,36 +630,50 @@ func (p *Plugin) handleRollbackCommandResponse(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Get the netlify client
-	netlifyClient, ctx := p.getNetlifyClient()
-	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
-	if err != nil {
-		p.API.SendEphemeralPost(userID, &model.Post{
-			UserId:    p.BotUserID,
-			ChannelId: channelID,
-			Message: fmt.Sprintf(
-				":exclamation: Authentication failed\n"+
-					"*Error : %v*", err.Error()),
-		})
-		return
+	page := 1
+	pagePassed, _ := intergrationResponseFromCommand.Context["page"].(string)
+	if pageParsed, err := strconv.Atoi(pagePassed); err == nil && pageParsed > 0 {
+		page = pageParsed
+	}
+
+	// Filters passed along with the rollback command
+	branchFilter, _ := intergrationResponseFromCommand.Context["branch"].(string)
+	contextFilter, _ := intergrationResponseFromCommand.Context["context"].(string)
+	sinceFilter, _ := intergrationResponseFromCommand.Context["since"].(string)
+	untilFilter, _ := intergrationResponseFromCommand.Context["until"].(string)
+
+	var since, until time.Time
+	if len(sinceFilter) != 0 {
+		since, _ = parseDateFlag(sinceFilter)
+	}
+	if len(untilFilter) != 0 {
+		until, _ = parseDateFlag(untilFilter)
+
+		// Deploys of the whole day are included when until is a date
+		if _, err := time.Parse(CommandFlagDateLayout, untilFilter); err == nil {
+			until = until.AddDate(0, 0, 1)
+		}
 	}
 
 	originalPostID := intergrationResponseFromCommand.PostId
 
-	// Update the message of original dropdown message post
-	p.API.UpdateEphemeralPost(userID, &model.Post{
-		Id:        originalPostID,
-		UserId:    p.BotUserID,
-		ChannelId: channelID,
-		Message:   fmt.Sprintf(":one: Fetching list of 5 most recent deploys of **%v** site.", siteName),
-	})
+	if isSelectedFromDropdown == true {
+		// Update the message of original dropdown message post
+		p.API.UpdateEphemeralPost(userID, &model.Post{
+			Id:        originalPostID,
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message:   fmt.Sprintf(":one: Fetching list of recent deploys of **%v** site.", siteName),
+		})
 
-	listSiteBuildsParams := &netlifyPlumbingModels.ListSiteBuildsParams{
-		SiteID:  siteID,
-		Context: ctx,
+		// Warn the user if production publishing of the site is locked
+		deployLockWarning := p.getDeployLockWarning(userID, siteID, siteName)
+		if len(deployLockWarning) != 0 {
+			p.sendMessageFromBot(channelID, userID, true, deployLockWarning)
+		}
 	}
 
-	listSiteDeploysResponse, err := netlifyClient.Operations.ListSiteBuilds(listSiteBuildsParams, netlifyClientCredentials)
+	listSiteDeploys, hasOlderDeploys, err := p.listRollbackDeploys(userID, siteID, page, RollbackDeploysPerPage, branchFilter, contextFilter, since, until)
 	if err != nil {
 		p.API.SendEphemeralPost(userID, &model.Post{
 			UserId:    p.BotUserID,
@@ -561,8 +685,8 @@ func (p *Plugin) handleRollbackCommandResponse(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// All of the previous builds of the site
-	listSiteDeploys := listSiteDeploysResponse.GetPayload()
+	// Tags given to the deploys from Mattermost, not having them shouldn't stop a rollback
+	deployTags, _ := p.getDeployTagsForSite(siteID)
 
 	// Create an empty array of options we will be using for dropdown
 	var sitesDeployListDropdownOptions []*model.PostActionOptions
@@ -570,88 +694,207 @@ func (p *Plugin) handleRollbackCommandResponse(w http.ResponseWriter, r *http.Re
 	// Create a table with just the header, rows will fill up in the loop
 	var deployMarkdownTable string = MarkdownDeployListTableHeader
 
-	var i int = 1
-	for index, deploy := range listSiteDeploys {
-		// Take only successfull deploys into consideration
-		if deploy.Done == true && len(deploy.Error) == 0 {
-			if i <= 5 {
-				// To restrict only to 5 entries
-				i = i + 1
-
-				var deploySHA string = "*Deployed via webhook or manually*"
-				if deploy.Sha != "" {
-					deploySHA = deploy.Sha
-				}
+	for _, deploy := range listSiteDeploys {
+		deployTitle := describeDeployTitle(deploy.Title)
+		deploySHA := describeDeploySHA(deploy.CommitRef)
+		deployAuthor := describeDeployAuthor(deploy.Committer)
 
-				var deployedAt string = "-"
-				if deploy.CreatedAt != "" {
-					lastDeployedAtParsed, err := time.Parse(NetlifyDateLayout, deploy.CreatedAt)
-					if err == nil {
-						deployedAt = lastDeployedAtParsed.Format(time.RFC822)
-					}
-				}
+		var deployedAt string = formatNetlifyDate(deploy.PublishedAt)
+		if len(deploy.PublishedAt) == 0 {
+			deployedAt = formatNetlifyDate(deploy.CreatedAt)
+		}
 
-				// Construct table for details of deploy
-				var deployTableRow string = fmt.Sprintf("| %v | %v | %v | %v |", index, deploySHA, deployedAt, deploy.DeployID)
-				deployMarkdownTable = fmt.Sprintf("%v\n%v", deployMarkdownTable, deployTableRow)
+		// Construct table for details of deploy
+		var deployTableRow string = fmt.Sprintf("| %v | %v | %v | %v | %v | %v | %v | %v |",
+			deployTitle, deploySHA, deployAuthor, deploy.Branch, deploy.Context, deployedAt, deploy.ID, describeDeployTags(deployTags[deploy.ID]))
+		deployMarkdownTable = fmt.Sprintf("%v\n%v", deployMarkdownTable, deployTableRow)
 
-				siteDeployOption := &model.PostActionOptions{
-					Text:  fmt.Sprintf("To sequence No.%v", index),
-					Value: fmt.Sprintf("%v %v %v", siteID, siteName, deploy.DeployID),
-				}
-				// Store name, id information of all the sites inside the dropdown option
-				sitesDeployListDropdownOptions = append(sitesDeployListDropdownOptions, siteDeployOption)
-			} else {
-				break
-			}
+		siteDeployOption := &model.PostActionOptions{
+			Text:  fmt.Sprintf("%v (%v by %v, %v)", deployTitle, deploySHA, deployAuthor, deployedAt),
+			Value: fmt.Sprintf("%v %v %v", siteID, siteName, deploy.ID),
+		}
+		// Store name, id information of all the sites inside the dropdown option
+		sitesDeployListDropdownOptions = append(sitesDeployListDropdownOptions, siteDeployOption)
+	}
+
+	// Context shared by the older and newer buttons, so filters stay the same across pages
+	pageButtonContext := func(toPage int) map[string]interface{} {
+		return map[string]interface{}{
+			"actionSecret": actionSecret,
+			"siteID":       siteID,
+			"siteName":     siteName,
+			"page":         strconv.Itoa(toPage),
+			"branch":       branchFilter,
+			"context":      contextFilter,
+			"since":        sinceFilter,
+			"until":        untilFilter,
 		}
 	}
 
-	// If there are no deploys, give the error message
-	if len(sitesDeployListDropdownOptions) == 0 {
+	var sitesDeployListActions []*model.PostAction
+
+	if len(sitesDeployListDropdownOptions) != 0 {
+		// Construct a dropdown
+		sitesDeployListActions = append(sitesDeployListActions, &model.PostAction{
+			Type:     model.POST_ACTION_TYPE_SELECT,
+			Name:     "Select a previous deploy version",
+			Disabled: false,
+			Options:  sitesDeployListDropdownOptions,
+			Integration: &model.PostActionIntegration{
+				// When the user selects an option following route will be handeled
+				URL: fmt.Sprintf("%s/plugins/netlify/command/rollback", *siteURL),
+				Context: map[string]interface{}{
+					"actionSecret": actionSecret,
+				},
+			},
+		})
+	}
+
+	if hasOlderDeploys == true {
+		sitesDeployListActions = append(sitesDeployListActions, &model.PostAction{
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Name: "Older",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("%s/plugins/netlify/command/rollback-builds", *siteURL),
+				Context: pageButtonContext(page + 1),
+			},
+		})
+	}
+
+	if page > 1 {
+		sitesDeployListActions = append(sitesDeployListActions, &model.PostAction{
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Name: "Newer",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("%s/plugins/netlify/command/rollback-builds", *siteURL),
+				Context: pageButtonContext(page - 1),
+			},
+		})
+	}
+
+	// If there are no deploys on first page, give the error message
+	if len(sitesDeployListActions) == 0 {
 		p.API.SendEphemeralPost(userID, &model.Post{
 			UserId:    p.BotUserID,
 			ChannelId: channelID,
 			Message: fmt.Sprintf(
-				":white-flag: There are no valid deploys with **%v** site.\n", siteName),
+				":white_flag: There are no valid deploys with **%v** site.\n", siteName),
 		})
 		return
 	}
 
-	// Post a message with detail info of top 5 builds
-	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(":chains: List of latest 5 releases of **%v** Netlify site\n%v", siteName, deployMarkdownTable))
-
-	// Construct a dropdown
-	sitesDeployListDropdown := &model.PostAction{
-		Type:     model.POST_ACTION_TYPE_SELECT,
-		Name:     "Select a previous deploy version",
-		Disabled: false,
-		Options:  sitesDeployListDropdownOptions,
-		Integration: &model.PostActionIntegration{
-			// When the user selects an option following route will be handeled
-			URL: fmt.Sprintf("%s/plugins/netlify/command/rollback", *siteURL),
-			Context: map[string]interface{}{
-				"actionSecret": actionSecret,
-			},
-		},
+	sitesDeployListText := fmt.Sprintf("Select a deploy version of %v site which you would like to rollback to:\n%v", siteName, deployMarkdownTable)
+	if len(sitesDeployListDropdownOptions) == 0 {
+		sitesDeployListText = "*No more deploys matched the filters, try newer deploys*"
 	}
 
+	sitesDeployListFooter := fmt.Sprintf("Page %v of successful deploys%v. Refer the table of deploys before making your selection",
+		page, describeRollbackFilters(branchFilter, contextFilter, sinceFilter, untilFilter))
+
 	sitesDeployListCommandInteractiveMessage := &model.SlackAttachment{
 		Title:   fmt.Sprintf("Rollback *%v* sites to previous versions", siteName),
-		Text:    fmt.Sprintf("Select a deploy version of %v site which you would like to rollback to:\n", siteName),
-		Actions: []*model.PostAction{sitesDeployListDropdown},
-		Footer:  "Before proceding refer the table of the most recent successful deploys posted above and then make your selection",
+		Text:    sitesDeployListText,
+		Actions: sitesDeployListActions,
+		Footer:  sitesDeployListFooter,
 	}
 
-	sitesDeployListCommandPost := &model.Post{
+	// Replace the original post, which is either the site dropdown or the previous page
+	p.API.UpdateEphemeralPost(userID, &model.Post{
+		Id:        originalPostID,
 		UserId:    p.BotUserID,
 		ChannelId: channelID,
 		Props: map[string]interface{}{
 			"attachments": []*model.SlackAttachment{sitesDeployListCommandInteractiveMessage},
 		},
+	})
+}
+
+// listRollbackDeploys returns a page of successful deploys of the site, newest first, which match the filters.
+// Context and dates are only known after deploys are fetched, so deploys are fetched until the page is full,
+// deploys older than since are reached or there are no more of them. It also tells if there are older deploys to page through.
+func (p *Plugin) listRollbackDeploys(userID, siteID string, page, perPage int, branch, deployContext string, since, until time.Time) ([]*NetlifyDeploy, bool, error) {
+	// Only successful deploys can be rolled back to, branch is filtered at Netlify itself
+	deploysQuery := url.Values{}
+	deploysQuery.Set("state", NetlifyEventStateDeployCreated)
+	if len(branch) != 0 {
+		deploysQuery.Set("branch", branch)
 	}
 
-	p.API.SendEphemeralPost(userID, sitesDeployListCommandPost)
+	// Matching deploys of the earlier pages are skipped, one more than the page tells if there are older ones
+	deploysToSkip := (page - 1) * perPage
+
+	var deploys []*NetlifyDeploy
+	for fetchedPage := 1; ; fetchedPage++ {
+		deploysOfPage, err := p.listSiteDeploys(userID, siteID, fetchedPage, RollbackDeploysFetchedPerPage, deploysQuery)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, deploy := range deploysOfPage {
+			// Take only successfull deploys into consideration
+			if deploy.State != NetlifyEventStateDeployCreated || len(deploy.ErrorMessage) != 0 {
+				continue
+			}
+
+			if len(branch) != 0 && deploy.Branch != branch {
+				continue
+			}
+
+			if len(deployContext) != 0 && deploy.Context != deployContext {
+				continue
+			}
+
+			deployCreatedAt, err := time.Parse(NetlifyDateLayout, deploy.CreatedAt)
+			if err == nil {
+				// Deploys are sorted newest first, so rest of them are older too
+				if !since.IsZero() && deployCreatedAt.Before(since) {
+					return deploys, false, nil
+				}
+				if !until.IsZero() && deployCreatedAt.After(until) {
+					continue
+				}
+			}
+
+			if deploysToSkip > 0 {
+				deploysToSkip--
+				continue
+			}
+
+			if len(deploys) == perPage {
+				return deploys, true, nil
+			}
+
+			deploys = append(deploys, deploy)
+		}
+
+		// A page smaller than asked for means there are no older deploys
+		if len(deploysOfPage) < RollbackDeploysFetchedPerPage {
+			return deploys, false, nil
+		}
+	}
+}
+
+// describeRollbackFilters returns the filters applied on rollback history in readable form
+func describeRollbackFilters(branch, deployContext, since, until string) string {
+	var filters []string
+	if len(branch) != 0 {
+		filters = append(filters, "branch "+branch)
+	}
+	if len(deployContext) != 0 {
+		filters = append(filters, "context "+deployContext)
+	}
+	if len(since) != 0 {
+		filters = append(filters, "since "+since)
+	}
+	if len(until) != 0 {
+		filters = append(filters, "until "+until)
+	}
+
+	if len(filters) == 0 {
+		return ""
+	}
+
+	return " filtered by " + strings.Join(filters, ", ")
 }
 
 func (p *Plugin) handleRollbackBuildSelectResponse(w http.ResponseWriter, r *http.Request) {
@@ -694,9 +937,20 @@ func (p *Plugin) handleRollbackBuildSelectResponse(w http.ResponseWriter, r *htt
 		return
 	}
 
-	selectedOption := intergrationResponseFromCommand.Context["selected_option"].(string)
+	// Comprises of id name deployID
+	selectedOption, ok := intergrationResponseFromCommand.Context["selected_option"].(string)
+
 	// Get the information from Body which contain the interactive Message Attachment we sent from /disconnect command
 	selectedOptionsValue := strings.Fields(selectedOption)
+	if !ok || len(selectedOptionsValue) < 3 {
+		p.API.SendEphemeralPost(userID, &model.Post{
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(
+				":exclamation: One of more values while selecting from dropdown were empty"),
+		})
+		return
+	}
 
 	// Extract the selected site information
 	siteID := selectedOptionsValue[0]
@@ -704,7 +958,7 @@ func (p *Plugin) handleRollbackBuildSelectResponse(w http.ResponseWriter, r *htt
 	siteDeployID := selectedOptionsValue[2]
 
 	// Check if any is empty
-	if len(selectedOptionsValue) == 0 || len(siteID) == 0 || len(siteDeployID) == 0 {
+	if len(siteID) == 0 || len(siteDeployID) == 0 {
 		p.API.SendEphemeralPost(userID, &model.Post{
 			UserId:    p.BotUserID,
 			ChannelId: channelID,
@@ -714,6 +968,23 @@ func (p *Plugin) handleRollbackBuildSelectResponse(w http.ResponseWriter, r *htt
 		return
 	}
 
+	originalPostID := intergrationResponseFromCommand.PostId
+
+	// Update the message of original dropdown message post
+	p.API.UpdateEphemeralPost(userID, &model.Post{
+		Id:        originalPostID,
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Message:   fmt.Sprintf(":two: Preparing to rollback %v site to %v deploy id state", siteName, siteDeployID),
+	})
+
+	p.rollbackSiteToDeploy(userID, channelID, siteID, siteName, siteDeployID, true)
+}
+
+// rollbackSiteToDeploy restores the site to one of its previous deploys and confirms it in background once published.
+// Undo is offered only if asked for, so undoing a rollback doesn't offer to undo itself.
+// Any failure is conveyed to the user as an ephemeral post, and false is returned.
+func (p *Plugin) rollbackSiteToDeploy(userID, channelID, siteID, siteName, siteDeployID string, isUndoOffered bool) bool {
 	// Get the netlify client
 	netlifyClient, ctx := p.getNetlifyClient()
 	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
@@ -725,18 +996,26 @@ func (p *Plugin) handleRollbackBuildSelectResponse(w http.ResponseWriter, r *htt
 				":exclamation: Authentication failed\n"+
 					"*Error : %v*", err.Error()),
 		})
-		return
+		return false
 	}
 
-	originalPostID := intergrationResponseFromCommand.PostId
+	// Warn the user if production publishing of the site is locked
+	deployLockWarning := p.getDeployLockWarning(userID, siteID, siteName)
+	if len(deployLockWarning) != 0 {
+		p.sendMessageFromBot(channelID, userID, true, deployLockWarning)
+	}
 
-	// Update the message of original dropdown message post
-	p.API.UpdateEphemeralPost(userID, &model.Post{
-		Id:        originalPostID,
-		UserId:    p.BotUserID,
-		ChannelId: channelID,
-		Message:   fmt.Sprintf(":two: Preparing to rollback %v site to %v deploy id state", siteName, siteDeployID),
-	})
+	// Record the currently published deploy, so that the rollback can be undone
+	getSiteParams := &netlifyPlumbingModels.GetSiteParams{
+		SiteID:  siteID,
+		Context: ctx,
+	}
+
+	var previousDeployID string
+	getSiteResponse, err := netlifyClient.Operations.GetSite(getSiteParams, netlifyClientCredentials)
+	if err == nil && getSiteResponse.GetPayload().PublishedDeploy != nil {
+		previousDeployID = getSiteResponse.GetPayload().PublishedDeploy.ID
+	}
 
 	// Restore site to prev x state
 	restoreSiteDeployParams := &netlifyPlumbingModels.RestoreSiteDeployParams{
@@ -744,15 +1023,302 @@ func (p *Plugin) handleRollbackBuildSelectResponse(w http.ResponseWriter, r *htt
 		SiteID:   siteID,
 		Context:  ctx,
 	}
-	netlifyClient.Operations.RestoreSiteDeploy(restoreSiteDeployParams, netlifyClientCredentials)
+	_, err = netlifyClient.Operations.RestoreSiteDeploy(restoreSiteDeployParams, netlifyClientCredentials)
+	if err != nil {
+		p.API.SendEphemeralPost(userID, &model.Post{
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(
+				":exclamation: Failed to rollback **%v** site to %v deploy.\n"+
+					"*Error : %v*", siteName, siteDeployID, err.Error()),
+		})
+		return false
+	}
+
+	rollbackPost := &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Message: fmt.Sprintf(
+			":satellite: Mattermost Netlify Bot has successfully asked Netlify to rollback **%v** site to a previously version by ID %v.\n"+
+				"*Since this is an update, you will not receive a build notification. We will confirm here once the rollback is live.*", siteName, siteDeployID),
+	}
+
+	// Offer to go back to the deploy which was published before the rollback
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if isUndoOffered == true && siteURL != nil && len(previousDeployID) != 0 && previousDeployID != siteDeployID {
+		undoButton := &model.PostAction{
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Name: "Undo",
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/netlify/command/rollback-undo", *siteURL),
+				Context: map[string]interface{}{
+					"actionSecret":     p.getConfiguration().EncryptionKey,
+					"siteID":           siteID,
+					"siteName":         siteName,
+					"previousDeployID": previousDeployID,
+					"rolledBackAt":     strconv.FormatInt(model.GetMillis(), 10),
+				},
+			},
+		}
+
+		rollbackPost.Props = map[string]interface{}{
+			"attachments": []*model.SlackAttachment{{
+				Text:    fmt.Sprintf("Previously published deploy was %v", previousDeployID),
+				Actions: []*model.PostAction{undoButton},
+				Footer:  fmt.Sprintf("Undo is available for %v after the rollback", RollbackUndoWindow),
+			}},
+		}
+	}
 
 	// Successfully post a message we asked netlify to re deploy
+	p.API.CreatePost(rollbackPost)
+
+	// Restoring is not instant, confirm in background once the deploy is published
+	go p.verifyRollbackOfSite(userID, channelID, siteID, siteName, siteDeployID)
+
+	return true
+}
+
+func (p *Plugin) handleRollbackUndoResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		p.API.SendEphemeralPost(userID, &model.Post{
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(
+				":exclamation: Authentication failed\n"),
+		})
+		return
+	}
+
+	siteID, _ := intergrationResponseFromCommand.Context["siteID"].(string)
+	siteName, _ := intergrationResponseFromCommand.Context["siteName"].(string)
+	previousDeployID, _ := intergrationResponseFromCommand.Context["previousDeployID"].(string)
+	rolledBackAtPassed, _ := intergrationResponseFromCommand.Context["rolledBackAt"].(string)
+
+	rolledBackAt, err := strconv.ParseInt(rolledBackAtPassed, 10, 64)
+	if len(siteID) == 0 || len(siteName) == 0 || len(previousDeployID) == 0 || err != nil {
+		p.API.SendEphemeralPost(userID, &model.Post{
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(
+				":exclamation: One of more values of the rollback to undo were empty"),
+		})
+		return
+	}
+
+	// Undo is only allowed for a short while after the rollback
+	if model.GetMillis()-rolledBackAt > int64(RollbackUndoWindow/time.Millisecond) {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":hourglass: Undo is available only for %v after the rollback. You can still rollback **%v** site to %v deploy with `/netlify rollback`",
+			RollbackUndoWindow, siteName, previousDeployID))
+		return
+	}
+
+	// Undo is done with the Netlify account of the user who asked for it, same as a rollback,
+	// so only users whose Netlify account can deploy the site are able to undo
+	accessToken, err := p.getNetlifyUserAccessTokenFromStore(userID)
+	if err != nil || len(accessToken) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, "You must connect your Netlify account first.\nPlease run `/netlify connect`")
+		return
+	}
+
+	// Remove the undo button, so that the same rollback isn't undone twice
+	rollbackPost, appErr := p.API.GetPost(intergrationResponseFromCommand.PostId)
+	var rollbackPostBeforeUndo *model.Post
+	if appErr == nil {
+		rollbackPostBeforeUndo = rollbackPost.Clone()
+		rollbackPost.Props = map[string]interface{}{}
+		rollbackPost.Message = fmt.Sprintf("%v\n:leftwards_arrow_with_hook: *Undo requested by %v*", rollbackPost.Message, p.getUserMention(userID))
+		p.API.UpdatePost(rollbackPost)
+	}
+
+	isRolledBack := p.rollbackSiteToDeploy(userID, channelID, siteID, siteName, previousDeployID, false)
+
+	// Bring the undo button back so it can be tried again
+	if isRolledBack == false && rollbackPostBeforeUndo != nil {
+		p.API.UpdatePost(rollbackPostBeforeUndo)
+	}
+}
+
+// verifyRollbackOfSite polls the site until the restored deploy becomes its published deploy
+// or until the verification times out, and then posts the result in the channel.
+func (p *Plugin) verifyRollbackOfSite(userID, channelID, siteID, siteName, deployID string) {
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Authentication failed while verifying rollback\n"+
+				"*Error : %v*", err.Error()))
+		return
+	}
+
+	getSiteParams := &netlifyPlumbingModels.GetSiteParams{
+		SiteID:  siteID,
+		Context: ctx,
+	}
+
+	var currentlyPublishedDeployID string
+	var siteLiveURL string
+
+	deadline := time.Now().Add(RollbackVerificationTimeout)
+	for time.Now().Before(deadline) {
+		// Stop verifying if the plugin is deactivated meanwhile
+		select {
+		case <-time.After(RollbackVerificationInterval):
+		case <-p.stopBackgroundJobs:
+			return
+		}
+
+		getSiteResponse, err := netlifyClient.Operations.GetSite(getSiteParams, netlifyClientCredentials)
+		if err != nil {
+			// Try again on next poll, site might be busy while restoring
+			continue
+		}
+
+		site := getSiteResponse.GetPayload()
+
+		siteLiveURL = site.URL
+		if len(site.SslURL) != 0 {
+			siteLiveURL = site.SslURL
+		}
+
+		if site.PublishedDeploy == nil {
+			continue
+		}
+
+		currentlyPublishedDeployID = site.PublishedDeploy.ID
+		if currentlyPublishedDeployID == deployID {
+			p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(
+				":white_check_mark: Rollback of **%v** site is confirmed, deploy %v is now live at %v", siteName, deployID, siteLiveURL))
+			return
+		}
+	}
+
+	if len(currentlyPublishedDeployID) == 0 {
+		currentlyPublishedDeployID = "*unknown*"
+	}
+
+	if len(siteLiveURL) == 0 {
+		siteLiveURL = "*unknown*"
+	}
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(
+		":x: Rollback of **%v** site to deploy %v could not be confirmed within %v.\n"+
+			"Currently published deploy is %v, please check the site at %v",
+		siteName, deployID, RollbackVerificationTimeout, currentlyPublishedDeployID, siteLiveURL))
+}
+
+func (p *Plugin) handleCancelCommandResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+	originalPostID := intergrationResponseFromCommand.PostId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		p.API.SendEphemeralPost(userID, &model.Post{
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(
+				":exclamation: Authentication failed\n"),
+		})
+		return
+	}
+
+	var siteID, siteName, deployID string
+
+	// Selecting from cancel command dropdown passes "id name deployID" whereas
+	// cancel button on build notification passes them as separate context values
+	selectedOption, isSelectedFromDropdown := intergrationResponseFromCommand.Context["selected_option"].(string)
+	if isSelectedFromDropdown == true {
+		selectedOptionsValue := strings.Fields(selectedOption)
+		if len(selectedOptionsValue) == 3 {
+			siteID = selectedOptionsValue[0]
+			siteName = selectedOptionsValue[1]
+			deployID = selectedOptionsValue[2]
+		}
+	} else {
+		siteID, _ = intergrationResponseFromCommand.Context["siteID"].(string)
+		siteName, _ = intergrationResponseFromCommand.Context["siteName"].(string)
+		deployID, _ = intergrationResponseFromCommand.Context["deployID"].(string)
+	}
+
+	// Check if any is empty
+	if len(siteID) == 0 || len(siteName) == 0 || len(deployID) == 0 {
+		p.API.SendEphemeralPost(userID, &model.Post{
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(
+				":exclamation: One of more values while selecting the deploy were empty"),
+		})
+		return
+	}
+
+	// Cancel button on notification can be pressed by anyone in the channel
+	accessToken, err := p.getNetlifyUserAccessTokenFromStore(userID)
+	if err != nil || len(accessToken) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, "You must connect your Netlify account first.\nPlease run `/netlify connect`")
+		return
+	}
+
+	if isSelectedFromDropdown == true {
+		// Update the message of original dropdown message post
+		p.API.UpdateEphemeralPost(userID, &model.Post{
+			Id:        originalPostID,
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message:   fmt.Sprintf(":hourglass: Asking Netlify to cancel %v deploy of **%v** site", deployID, siteName),
+		})
+	}
+
+	// Cancel operation is not part of netlify library client, hence calling the api directly
+	cancelledDeploy := &netlifyModels.Deploy{}
+	err = p.sendNetlifyAPIRequest(userID, http.MethodPost, fmt.Sprintf("/deploys/%v/cancel", deployID), nil, nil, cancelledDeploy)
+	if err != nil {
+		p.API.SendEphemeralPost(userID, &model.Post{
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(
+				":exclamation: Failed to cancel %v deploy of **%v** site.\n"+
+					"*Error : %v*", deployID, siteName, err.Error()),
+		})
+		return
+	}
+
 	p.API.CreatePost(&model.Post{
 		UserId:    p.BotUserID,
 		ChannelId: channelID,
 		Message: fmt.Sprintf(
-			":satellite: Mattermost Netlify Bot has successfully asked Netlify to rollback **%v** site to a previously version by ID %v.\n"+
-				"*Since this is an update, you probably will not receive a build notification, You can visit the URL to see if its rolled back.*", siteName, siteDeployID),
+			":octagonal_sign: %v cancelled %v deploy of **%v** site.", p.getUserMention(userID), deployID, siteName),
 	})
 }
 
@@ -995,6 +1561,35 @@ func (p *Plugin) handleSiteCommandResponse(w http.ResponseWriter, r *http.Reques
 		site.BuildSettings.RepoURL, site.BuildSettings.RepoBranch, siteLogsPriv,
 		siteEnchance, siteBundleCSS, siteMinifyCSS, siteBundleJS, siteMinifyJS, sitePrettyURL, siteOptimizeImg, site.AdminURL)
 
-	p.sendMessageFromBot(channelID, "", false, siteInformationMessage)
+	siteInformationPost := &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Message:   siteInformationMessage,
+	}
+
+	// Anyone in the channel can edit the settings with their own Netlify account
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL != nil {
+		editSettingsButton := &model.PostAction{
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Name: "Edit settings",
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/netlify/command/site-settings", *siteURL),
+				Context: map[string]interface{}{
+					"actionSecret": p.getConfiguration().EncryptionKey,
+					"siteID":       site.ID,
+					"siteName":     site.Name,
+				},
+			},
+		}
+
+		siteInformationPost.Props = map[string]interface{}{
+			"attachments": []*model.SlackAttachment{{
+				Actions: []*model.PostAction{editSettingsButton},
+			}},
+		}
+	}
+
+	p.API.CreatePost(siteInformationPost)
 
 }
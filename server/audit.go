@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// AuditRecord is a change made to a Netlify site or team account from Mattermost
+type AuditRecord struct {
+	UserID    string `json:"user_id"`
+	Action    string `json:"action"`
+	Details   string `json:"details"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// recordAuditEvent writes the change made to a site into the server logs and its audit records.
+// Details must never carry secrets like values of environment variables.
+func (p *Plugin) recordAuditEvent(siteID string, userID string, action string, details string) error {
+	p.API.LogInfo("Netlify site changed from Mattermost", "site_id", siteID, "user_id", userID, "action", action, "details", details)
+
+	return p.appendAuditRecord(siteID+NetlifyAuditRecordsKVIdentifier, userID, action, details)
+}
+
+// recordTeamAuditEvent writes the change made to a team account into the server logs and its audit records,
+// which are kept apart from the audit records of sites.
+func (p *Plugin) recordTeamAuditEvent(accountID string, userID string, action string, details string) error {
+	p.API.LogInfo("Netlify team changed from Mattermost", "account_id", accountID, "user_id", userID, "action", action, "details", details)
+
+	return p.appendAuditRecord(accountID+NetlifyTeamAuditRecordsKVIdentifier, userID, action, details)
+}
+
+// appendAuditRecord adds a record to the audit records stored under the key, keeping only the most recent ones
+func (p *Plugin) appendAuditRecord(auditRecordsKey string, userID string, action string, details string) error {
+	auditRecords, err := p.getAuditRecords(auditRecordsKey)
+	if err != nil {
+		return err
+	}
+
+	auditRecords = append(auditRecords, &AuditRecord{
+		UserID:    userID,
+		Action:    action,
+		Details:   details,
+		CreatedAt: model.GetMillis(),
+	})
+
+	// Only the most recent records are kept
+	if len(auditRecords) > AuditRecordsPerSiteLimit {
+		auditRecords = auditRecords[len(auditRecords)-AuditRecordsPerSiteLimit:]
+	}
+
+	auditRecordsInBytes, err := json.Marshal(auditRecords)
+	if err != nil {
+		return err
+	}
+
+	appErr := p.API.KVSet(auditRecordsKey, auditRecordsInBytes)
+	if appErr != nil {
+		return appErr
+	}
+
+	return nil
+}
+
+// getAuditRecords returns changes from Mattermost stored under the key, oldest first
+func (p *Plugin) getAuditRecords(auditRecordsKey string) ([]*AuditRecord, error) {
+	auditRecordsInBytes, appErr := p.API.KVGet(auditRecordsKey)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// It returns nil if value is not found
+	if auditRecordsInBytes == nil {
+		return []*AuditRecord{}, nil
+	}
+
+	var auditRecords []*AuditRecord
+	err := json.Unmarshal(auditRecordsInBytes, &auditRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	return auditRecords, nil
+}
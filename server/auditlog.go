@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+)
+
+func (p *Plugin) handleAuditCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	// Eg. /netlify audit my-team --since 7d --actor jane --site blog
+	arguments, flags := parseCommandFlags(parameters)
+	if len(arguments) > 1 {
+		p.sendMessageFromBot(channelID, userID, true,
+			"Please mention at most one account eg. `/netlify audit [account] [--since 7d] [--actor name] [--site name]`")
+		return &model.CommandResponse{}, nil
+	}
+
+	if since, ok := flags["since"]; ok {
+		if _, err := parseDateFlag(since); err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":exclamation: Invalid value of --since flag. %v", err.Error()))
+			return &model.CommandResponse{}, nil
+		}
+	}
+
+	// Sites which were deleted can't be resolved anymore, so they are still filtered by what was passed
+	if siteSearchTerm, ok := flags["site"]; ok {
+		if site, err := p.resolveSite(userID, siteSearchTerm); err == nil {
+			flags["site"] = site.ID
+		}
+	}
+
+	account, err := p.getAccountFromCommandArgument(userID, strings.Join(arguments, ""))
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the team account\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	auditLogPost, err := p.getAccountAuditLogPost(userID, channelID, account.ID, account.Name, 1, flags["actor"], flags["site"], flags["since"])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get audit log of **%v** team.\n"+
+				"*Error : %v*", account.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	p.API.SendEphemeralPost(userID, auditLogPost)
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleAuditCommandResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	accountID, _ := intergrationResponseFromCommand.Context["accountID"].(string)
+	accountName, _ := intergrationResponseFromCommand.Context["accountName"].(string)
+	actorFilter, _ := intergrationResponseFromCommand.Context["actor"].(string)
+	siteFilter, _ := intergrationResponseFromCommand.Context["site"].(string)
+	sinceFilter, _ := intergrationResponseFromCommand.Context["since"].(string)
+	pagePassed, _ := intergrationResponseFromCommand.Context["page"].(string)
+
+	page, err := strconv.Atoi(pagePassed)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	auditLogPost, err := p.getAccountAuditLogPost(userID, channelID, accountID, accountName, page, actorFilter, siteFilter, sinceFilter)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get audit log of **%v** team.\n"+
+				"*Error : %v*", accountName, err.Error()))
+		return
+	}
+
+	// Replace the previous page with the one asked for
+	auditLogPost.Id = intergrationResponseFromCommand.PostId
+	p.API.UpdateEphemeralPost(userID, auditLogPost)
+}
+
+// getAccountAuditLogPost returns a post with a single page of audit events of the account in a table, along with buttons to move across pages
+func (p *Plugin) getAccountAuditLogPost(userID, channelID, accountID, accountName string, page int, actor, site, since string) (*model.Post, error) {
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		return nil, fmt.Errorf("Site URL is not defined in the App")
+	}
+
+	auditEvents, hasOlderEvents, err := p.listFilteredAccountAuditEvents(userID, accountID, page, AccountAuditLogPerPage, actor, site, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a table with just the header, rows will fill up in the loop
+	var auditLogMarkdownTable string = MarkdownAccountAuditLogTableHeader
+	for _, auditEvent := range auditEvents {
+		_, siteName := getAuditEventSite(auditEvent.Payload)
+		auditLogMarkdownTable = fmt.Sprintf("%v\n| %v | %v | %v | %v |", auditLogMarkdownTable,
+			formatNetlifyDate(auditEvent.Payload.Timestamp), escapeMarkdownTableCell(describeAuditEventActor(auditEvent.Payload)),
+			auditEvent.Payload.Action, siteName)
+	}
+
+	// Context shared by the older and newer buttons, so filters stay the same across pages
+	pageButtonContext := func(toPage int) map[string]interface{} {
+		return map[string]interface{}{
+			"actionSecret": p.getConfiguration().EncryptionKey,
+			"accountID":    accountID,
+			"accountName":  accountName,
+			"page":         strconv.Itoa(toPage),
+			"actor":        actor,
+			"site":         site,
+			"since":        since,
+		}
+	}
+
+	var auditLogActions []*model.PostAction
+
+	if hasOlderEvents == true {
+		auditLogActions = append(auditLogActions, &model.PostAction{
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Name: "Older",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("%s/plugins/netlify/command/audit", *siteURL),
+				Context: pageButtonContext(page + 1),
+			},
+		})
+	}
+
+	if page > 1 {
+		auditLogActions = append(auditLogActions, &model.PostAction{
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Name: "Newer",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("%s/plugins/netlify/command/audit", *siteURL),
+				Context: pageButtonContext(page - 1),
+			},
+		})
+	}
+
+	auditLogText := auditLogMarkdownTable
+	if len(auditEvents) == 0 {
+		auditLogText = "*No audit events matched the filters*"
+	}
+
+	auditLogAttachment := &model.SlackAttachment{
+		Title:   fmt.Sprintf("Audit log of %v team", accountName),
+		Text:    auditLogText,
+		Actions: auditLogActions,
+		Footer:  fmt.Sprintf("Page %v of audit events%v", page, describeAuditLogFilters(actor, site, since)),
+	}
+
+	return &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": []*model.SlackAttachment{auditLogAttachment},
+		},
+	}, nil
+}
+
+// listFilteredAccountAuditEvents returns a page of audit events of the account, newest first, which match the filters.
+// Actor and site are only known after events are fetched, so events are fetched until the page is full,
+// events older than since are reached or the log runs out. It also tells if there are older events to page through.
+func (p *Plugin) listFilteredAccountAuditEvents(userID, accountID string, page, perPage int, actor, site, since string) ([]*netlifyModels.AuditLog, bool, error) {
+	var sinceDate time.Time
+	if len(since) != 0 {
+		sinceDate, _ = parseDateFlag(since)
+	}
+
+	// Matching events of the earlier pages are skipped, one more than the page tells if there are older ones
+	auditEventsToSkip := (page - 1) * perPage
+
+	var auditEvents []*netlifyModels.AuditLog
+	for fetchedPage := 1; ; fetchedPage++ {
+		auditEventsQuery := url.Values{}
+		auditEventsQuery.Set("page", strconv.Itoa(fetchedPage))
+		auditEventsQuery.Set("per_page", strconv.Itoa(AccountAuditEventsFetchedPerPage))
+
+		// Netlify library doesn't support paging through audit events
+		var auditEventsOfPage []*netlifyModels.AuditLog
+		err := p.sendNetlifyAPIRequest(userID, http.MethodGet, fmt.Sprintf("/accounts/%v/audit", accountID), auditEventsQuery, nil, &auditEventsOfPage)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, auditEvent := range auditEventsOfPage {
+			if auditEvent.Payload == nil {
+				continue
+			}
+
+			// Events are sorted newest first, so rest of them are older too
+			auditEventTime, err := time.Parse(time.RFC3339, auditEvent.Payload.Timestamp)
+			if err == nil && !sinceDate.IsZero() && auditEventTime.Before(sinceDate) {
+				return auditEvents, false, nil
+			}
+
+			if len(actor) != 0 && !containsFold(auditEvent.Payload.ActorName, actor) && !containsFold(auditEvent.Payload.ActorEmail, actor) {
+				continue
+			}
+
+			if len(site) != 0 {
+				siteID, siteName := getAuditEventSite(auditEvent.Payload)
+				if siteID != site && !strings.EqualFold(siteName, site) {
+					continue
+				}
+			}
+
+			if auditEventsToSkip > 0 {
+				auditEventsToSkip--
+				continue
+			}
+
+			if len(auditEvents) == perPage {
+				return auditEvents, true, nil
+			}
+
+			auditEvents = append(auditEvents, auditEvent)
+		}
+
+		// A page smaller than asked for means there are no older events
+		if len(auditEventsOfPage) < AccountAuditEventsFetchedPerPage {
+			return auditEvents, false, nil
+		}
+	}
+}
+
+// getAuditEventSite returns id and name of the site an audit event is about, empty if it isn't about a site.
+// They are not part of the documented payload, so they are looked for both in the payload and in its traits.
+func getAuditEventSite(auditEventPayload *netlifyModels.AuditLogPayload) (string, string) {
+	var siteID, siteName string
+
+	lookIn := func(properties map[string]interface{}) {
+		if value, ok := properties["site_id"].(string); ok && len(siteID) == 0 {
+			siteID = value
+		}
+		if value, ok := properties["site_name"].(string); ok && len(siteName) == 0 {
+			siteName = value
+		}
+	}
+
+	lookIn(auditEventPayload.AuditLogPayload)
+	if traits, ok := auditEventPayload.AuditLogPayload["traits"].(map[string]interface{}); ok {
+		lookIn(traits)
+	}
+
+	if len(siteName) == 0 {
+		siteName = siteID
+	}
+
+	return siteID, siteName
+}
+
+func describeAuditEventActor(auditEventPayload *netlifyModels.AuditLogPayload) string {
+	switch {
+	case len(auditEventPayload.ActorName) != 0 && len(auditEventPayload.ActorEmail) != 0:
+		return fmt.Sprintf("%v (%v)", auditEventPayload.ActorName, auditEventPayload.ActorEmail)
+	case len(auditEventPayload.ActorName) != 0:
+		return auditEventPayload.ActorName
+	default:
+		return auditEventPayload.ActorEmail
+	}
+}
+
+func describeAuditLogFilters(actor, site, since string) string {
+	var filters []string
+	if len(actor) != 0 {
+		filters = append(filters, "actor "+actor)
+	}
+	if len(site) != 0 {
+		filters = append(filters, "site "+site)
+	}
+	if len(since) != 0 {
+		filters = append(filters, "since "+since)
+	}
+
+	if len(filters) == 0 {
+		return ""
+	}
+
+	return " filtered by " + strings.Join(filters, ", ")
+}
+
+func containsFold(s string, substring string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substring))
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
+)
+
+func (p *Plugin) handleBuildHooksCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	subcommand := ""
+	if len(parameters) != 0 {
+		subcommand = parameters[0]
+	}
+
+	switch {
+	// "/netlify buildhooks create <site> <branch> <title>"
+	case subcommand == "create" && len(parameters) >= 4:
+		p.createBuildHook(userID, channelID, parameters[1], parameters[2], strings.Join(parameters[3:], " "))
+	// "/netlify buildhooks delete <site> <hookID>"
+	case subcommand == "delete" && len(parameters) == 3:
+		site, err := p.resolveSiteForChange(userID, parameters[1])
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Failed to find the site\n"+
+					"*Error : %v*", err.Error()))
+			return &model.CommandResponse{}, nil
+		}
+
+		p.sendBuildHookDeleteConfirmation(userID, channelID, site.ID, site.Name, parameters[2], "")
+	// "/netlify buildhooks <site>"
+	case len(parameters) == 1:
+		site, err := p.resolveSite(userID, parameters[0])
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Failed to find the site\n"+
+					"*Error : %v*", err.Error()))
+			return &model.CommandResponse{}, nil
+		}
+
+		buildHooksPost, err := p.getBuildHooksPost(userID, channelID, site.ID, site.Name)
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Failed to get build hooks of **%v** site.\n"+
+					"*Error : %v*", site.Name, err.Error()))
+			return &model.CommandResponse{}, nil
+		}
+
+		p.API.SendEphemeralPost(userID, buildHooksPost)
+	default:
+		p.sendMessageFromBot(channelID, userID, true, "Please use one of the below buildhooks commands\n"+
+			"* `/netlify buildhooks <site>`\n"+
+			"* `/netlify buildhooks create <site> <branch> <title>`\n"+
+			"* `/netlify buildhooks delete <site> <hookID>`")
+	}
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleBuildHooksCommandResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+	originalPostID := intergrationResponseFromCommand.PostId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	actionToBeTaken, _ := intergrationResponseFromCommand.Context["action"].(string)
+	siteID, _ := intergrationResponseFromCommand.Context["siteID"].(string)
+	siteName, _ := intergrationResponseFromCommand.Context["siteName"].(string)
+	buildHookID, _ := intergrationResponseFromCommand.Context["buildHookID"].(string)
+
+	switch actionToBeTaken {
+	case ActionRevealBuildHookURL:
+		// URL is looked up again rather than carried in the post, so it only ever reaches the user who asked for it
+		buildHook, err := p.getBuildHook(userID, siteID, buildHookID)
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Failed to get %v build hook of **%v** site.\n"+
+					"*Error : %v*", buildHookID, siteName, err.Error()))
+			return
+		}
+
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":key: URL of **%v** build hook of **%v** site is %v\n"+
+				"Anyone with this URL can trigger builds of the site, please don't share it in channels.", buildHook.Title, siteName, buildHook.URL))
+	case ActionDeleteBuildHook:
+		// Deleting can't be undone, so it is confirmed first
+		p.sendBuildHookDeleteConfirmation(userID, channelID, siteID, siteName, buildHookID, originalPostID)
+	}
+}
+
+func (p *Plugin) sendBuildHookDeleteConfirmation(userID string, channelID string, siteID string, siteName string, buildHookID string, buildHooksPostID string) {
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		p.sendMessageFromBot(channelID, userID, true, "Error! Site URL is not defined in the App")
+		return
+	}
+
+	// Show what is going to be deleted, which also makes sure the hook exists
+	buildHook, err := p.getBuildHook(userID, siteID, buildHookID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find %v build hook of **%v** site.\n"+
+				"*Error : %v*", buildHookID, siteName, err.Error()))
+		return
+	}
+
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	buttonContext := func(action string) map[string]interface{} {
+		return map[string]interface{}{
+			"action":           action,
+			"actionSecret":     actionSecret,
+			"siteID":           siteID,
+			"siteName":         siteName,
+			"buildHookID":      buildHook.ID,
+			"buildHookTitle":   buildHook.Title,
+			"buildHooksPostID": buildHooksPostID,
+		}
+	}
+
+	deleteButton := &model.PostAction{
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Name: "Delete",
+		Integration: &model.PostActionIntegration{
+			URL:     fmt.Sprintf("%s/plugins/netlify/command/buildhooks-delete", *siteURL),
+			Context: buttonContext(ActionDeleteBuildHook),
+		},
+	}
+
+	cancelButton := &model.PostAction{
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Name: "Cancel",
+		Integration: &model.PostActionIntegration{
+			URL:     fmt.Sprintf("%s/plugins/netlify/command/buildhooks-delete", *siteURL),
+			Context: buttonContext(ActionCancel),
+		},
+	}
+
+	deleteMessageAttachment := &model.SlackAttachment{
+		Title: fmt.Sprintf("Delete build hook of %v", siteName),
+		Text: fmt.Sprintf(":warning: Are you sure you would like to delete **%v** build hook for `%v` branch?\n"+
+			"Anything triggering builds with its URL will stop building the site.", buildHook.Title, buildHook.Branch),
+		Actions: []*model.PostAction{deleteButton, cancelButton},
+	}
+
+	p.API.SendEphemeralPost(userID, &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": []*model.SlackAttachment{deleteMessageAttachment},
+		},
+	})
+}
+
+func (p *Plugin) handleBuildHookDeleteResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+	originalPostID := intergrationResponseFromCommand.PostId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	actionToBeTaken, _ := intergrationResponseFromCommand.Context["action"].(string)
+	siteID, _ := intergrationResponseFromCommand.Context["siteID"].(string)
+	siteName, _ := intergrationResponseFromCommand.Context["siteName"].(string)
+	buildHookID, _ := intergrationResponseFromCommand.Context["buildHookID"].(string)
+	buildHookTitle, _ := intergrationResponseFromCommand.Context["buildHookTitle"].(string)
+	buildHooksPostID, _ := intergrationResponseFromCommand.Context["buildHooksPostID"].(string)
+
+	if actionToBeTaken != ActionDeleteBuildHook {
+		p.API.UpdateEphemeralPost(userID, &model.Post{
+			Id:        originalPostID,
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message:   fmt.Sprintf(":ok_hand: **%v** build hook of **%v** site was not deleted.", buildHookTitle, siteName),
+		})
+		return
+	}
+
+	isDeleted := p.deleteBuildHook(userID, channelID, siteID, siteName, buildHookID)
+	if isDeleted == false {
+		return
+	}
+
+	p.API.DeleteEphemeralPost(userID, originalPostID)
+
+	// Refresh the build hooks post the deletion was asked from, so the deleted hook goes away
+	if len(buildHooksPostID) == 0 {
+		return
+	}
+
+	buildHooksPost, err := p.getBuildHooksPost(userID, channelID, siteID, siteName)
+	if err != nil {
+		return
+	}
+
+	buildHooksPost.Id = buildHooksPostID
+	p.API.UpdateEphemeralPost(userID, buildHooksPost)
+}
+
+// getBuildHooksPost returns a post listing every build hook of the site, along with buttons to reveal the URL of a hook and to delete it
+func (p *Plugin) getBuildHooksPost(userID string, channelID string, siteID string, siteName string) (*model.Post, error) {
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		return nil, fmt.Errorf("Site URL is not defined in the App")
+	}
+
+	buildHooks, err := p.listBuildHooks(userID, siteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buildHooks) == 0 {
+		return &model.Post{
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(":hook: **%v** site doesn't have any build hooks.\n"+
+				"Create one with `/netlify buildhooks create %v <branch> <title>`", siteName, siteName),
+		}, nil
+	}
+
+	// Oldest hooks are the likeliest to be stale, so they come first
+	sort.Slice(buildHooks, func(i, j int) bool {
+		return buildHooks[i].CreatedAt < buildHooks[j].CreatedAt
+	})
+
+	buildHooksAttachments := []*model.SlackAttachment{{
+		Text: fmt.Sprintf("#### Build hooks of %v", siteName),
+	}}
+
+	for _, buildHook := range buildHooks {
+		buttonContext := func(action string) map[string]interface{} {
+			return map[string]interface{}{
+				"action":       action,
+				"actionSecret": p.getConfiguration().EncryptionKey,
+				"siteID":       siteID,
+				"siteName":     siteName,
+				"buildHookID":  buildHook.ID,
+			}
+		}
+
+		buildHookButton := func(name string, action string) *model.PostAction {
+			return &model.PostAction{
+				Type: model.POST_ACTION_TYPE_BUTTON,
+				Name: name,
+				Integration: &model.PostActionIntegration{
+					URL:     fmt.Sprintf("%s/plugins/netlify/command/buildhooks", *siteURL),
+					Context: buttonContext(action),
+				},
+			}
+		}
+
+		buildHookText := fmt.Sprintf("*Branch* : %v\n"+
+			"*Created at* : %v\n"+
+			"*Hook ID* : %v\n"+
+			"*URL* : %v",
+			buildHook.Branch, formatNetlifyDate(buildHook.CreatedAt), buildHook.ID, redactBuildHookURL(buildHook.URL))
+
+		if buildHook.Title == MattermostNetlifyBuildHookTitle {
+			buildHookText = buildHookText + "\n*Used by `/netlify deploy`, it is created again on the next deploy if deleted*"
+		}
+
+		buildHooksAttachments = append(buildHooksAttachments, &model.SlackAttachment{
+			Title: buildHook.Title,
+			Text:  buildHookText,
+			Actions: []*model.PostAction{
+				buildHookButton("Show URL", ActionRevealBuildHookURL),
+				buildHookButton("Delete", ActionDeleteBuildHook),
+			},
+		})
+	}
+
+	return &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": buildHooksAttachments,
+		},
+	}, nil
+}
+
+func (p *Plugin) createBuildHook(userID string, channelID string, siteNameOrID string, branch string, title string) {
+	site, err := p.resolveSiteForChange(userID, siteNameOrID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return
+	}
+
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+		return
+	}
+
+	createSiteBuildHookParams := &netlifyPlumbingModels.CreateSiteBuildHookParams{
+		SiteID: site.ID,
+		BuildHook: &netlifyModels.BuildHook{
+			Title:  title,
+			Branch: branch,
+		},
+		Context: ctx,
+	}
+
+	createdSiteBuildHookResponse, err := netlifyClient.Operations.CreateSiteBuildHook(createSiteBuildHookParams, netlifyClientCredentials)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to create a build hook for **%v** site.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return
+	}
+
+	buildHook := createdSiteBuildHookResponse.GetPayload()
+
+	err = p.recordAuditEvent(site.ID, userID, "buildhook_create", fmt.Sprintf("%v build hook %v for %v branch", buildHook.ID, buildHook.Title, buildHook.Branch))
+	if err != nil {
+		p.API.LogError("Failed to save audit record of build hook change", "site_id", site.ID, "error", err.Error())
+	}
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(":hook: %v created **%v** build hook for `%v` branch of **%v** site at %v",
+		p.getUserMention(userID), buildHook.Title, buildHook.Branch, site.Name, redactBuildHookURL(buildHook.URL)))
+
+	p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":key: URL of **%v** build hook is %v\n"+
+		"Anyone with this URL can trigger builds of the site, please don't share it in channels.", buildHook.Title, buildHook.URL))
+}
+
+// deleteBuildHook deletes the build hook of the site and lets the channel know, returns false if it couldn't be deleted
+func (p *Plugin) deleteBuildHook(userID string, channelID string, siteID string, siteName string, buildHookID string) bool {
+	// Get the hook first, so the channel knows which one went away
+	buildHook, err := p.getBuildHook(userID, siteID, buildHookID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find %v build hook of **%v** site.\n"+
+				"*Error : %v*", buildHookID, siteName, err.Error()))
+		return false
+	}
+
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+		return false
+	}
+
+	deleteSiteBuildHookParams := &netlifyPlumbingModels.DeleteSiteBuildHookParams{
+		SiteID:  siteID,
+		ID:      buildHookID,
+		Context: ctx,
+	}
+
+	_, err = netlifyClient.Operations.DeleteSiteBuildHook(deleteSiteBuildHookParams, netlifyClientCredentials)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to delete **%v** build hook of **%v** site.\n"+
+				"*Error : %v*", buildHook.Title, siteName, err.Error()))
+		return false
+	}
+
+	err = p.recordAuditEvent(siteID, userID, "buildhook_delete", fmt.Sprintf("%v build hook %v for %v branch", buildHook.ID, buildHook.Title, buildHook.Branch))
+	if err != nil {
+		p.API.LogError("Failed to save audit record of build hook change", "site_id", siteID, "error", err.Error())
+	}
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(":wastebasket: %v deleted **%v** build hook for `%v` branch of **%v** site.",
+		p.getUserMention(userID), buildHook.Title, buildHook.Branch, siteName))
+
+	return true
+}
+
+// listBuildHooks returns all the build hooks of the site
+func (p *Plugin) listBuildHooks(userID string, siteID string) ([]*netlifyModels.BuildHook, error) {
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	listBuildHooksParams := &netlifyPlumbingModels.ListSiteBuildHooksParams{
+		SiteID:  siteID,
+		Context: ctx,
+	}
+
+	listBuildHooksResponse, err := netlifyClient.Operations.ListSiteBuildHooks(listBuildHooksParams, netlifyClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return listBuildHooksResponse.GetPayload(), nil
+}
+
+func (p *Plugin) getBuildHook(userID string, siteID string, buildHookID string) (*netlifyModels.BuildHook, error) {
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	getSiteBuildHookParams := &netlifyPlumbingModels.GetSiteBuildHookParams{
+		SiteID:  siteID,
+		ID:      buildHookID,
+		Context: ctx,
+	}
+
+	getSiteBuildHookResponse, err := netlifyClient.Operations.GetSiteBuildHook(getSiteBuildHookParams, netlifyClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return getSiteBuildHookResponse.GetPayload(), nil
+}
+
+// redactBuildHookURL hides the secret part of a build hook URL, leaving just enough to tell hooks apart
+func redactBuildHookURL(buildHookURL string) string {
+	secretStart := strings.LastIndex(buildHookURL, "/") + 1
+	if len(buildHookURL)-secretStart <= BuildHookURLVisibleCharacters {
+		return "`" + buildHookURL[:secretStart] + "••••••••`"
+	}
+
+	return "`" + buildHookURL[:secretStart+BuildHookURLVisibleCharacters] + "••••••••`"
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/mattermost/mattermost-server/v5/mlog"
 	"github.com/mattermost/mattermost-server/v5/model"
 	"github.com/mattermost/mattermost-server/v5/plugin"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
 )
 
 // Custom slash commands to setup
@@ -17,7 +18,7 @@ func getCommand() *model.Command {
 		DisplayName:      "Netlify",
 		Description:      "Integration with Netlify",
 		AutoComplete:     true,
-		AutoCompleteDesc: "Available commands: connect, disconnect, list, list id, deploy, rollback, subscribe, unsubscribe, subscriptions, site, me, help",
+		AutoCompleteDesc: "Available commands: connect, disconnect, list, list id, deploy, deploys, buildhooks, rollback, cancel, lock, unlock, env, domain, ssl, dns, functions, forms, submissions, hooks, subscribe, unsubscribe, subscriptions, site, status, search, team, audit, usage, me, help",
 		AutoCompleteHint: "[command]",
 	}
 }
@@ -65,35 +66,138 @@ func (p *Plugin) ExecuteCommand(c *plugin.Context, args *model.CommandArgs) (*mo
 		}
 	}
 
+	// "/netlify team members|invite|remove ..."
+	if action == "team" {
+		return p.handleTeamCommand(args, parameters)
+	}
+
+	// "/netlify audit [account] [--since 7d] [--actor name] [--site name]"
+	if action == "audit" {
+		return p.handleAuditCommand(args, parameters)
+	}
+
+	// "/netlify usage [account]"
+	if action == "usage" {
+		return p.handleUsageCommand(args, strings.Join(parameters, " "))
+	}
+
 	// "/netlify me"
 	if action == "me" {
 		return p.handleMeCommand(args)
 	}
 
-	// "/netlify deploy"
+	// "/netlify deploy [site]"
 	if action == "deploy" {
-		return p.handleDeployCommand(args)
+		// "/netlify deploy tag <site> <deployID> <tag> [note]"
+		if len(parameters) != 0 && parameters[0] == "tag" {
+			return p.handleDeployTagCommand(args, parameters[1:])
+		}
+		return p.handleDeployCommand(args, parameters)
 	}
 
-	// "/netlify rollback"
+	// "/netlify deploys <site> [--branch b] [--state error] [--since 7d] [--limit N] [--export csv]"
+	if action == "deploys" {
+		return p.handleDeploysCommand(args, parameters)
+	}
+
+	// "/netlify buildhooks <site>" or "/netlify buildhooks create|delete ..."
+	if action == "buildhooks" {
+		return p.handleBuildHooksCommand(args, parameters)
+	}
+
+	// "/netlify rollback [site]"
 	if action == "rollback" {
-		return p.handleRollbackCommand(args)
+		return p.handleRollbackCommand(args, parameters)
+	}
+
+	// "/netlify cancel <site>"
+	if action == "cancel" {
+		return p.handleCancelCommand(args, parameters)
+	}
+
+	// "/netlify lock <site> [reason]"
+	if action == "lock" {
+		return p.handleLockCommand(args, parameters)
+	}
+
+	// "/netlify unlock <site>"
+	if action == "unlock" {
+		return p.handleUnlockCommand(args, parameters)
+	}
+
+	// "/netlify env list|get|set|unset <site> [key] [value] [--redeploy]"
+	if action == "env" {
+		return p.handleEnvCommand(args, parameters)
+	}
+
+	// "/netlify domain set|add-alias|remove-alias <site> <domain>"
+	if action == "domain" {
+		return p.handleDomainCommand(args, parameters)
+	}
+
+	// "/netlify ssl <site>"
+	if action == "ssl" {
+		return p.handleSSLCommand(args, parameters)
+	}
+
+	// "/netlify dns zones|records|add|delete"
+	if action == "dns" {
+		return p.handleDNSCommand(args, parameters)
+	}
+
+	// "/netlify functions <site> [deployID]"
+	if action == "functions" {
+		return p.handleFunctionsCommand(args, parameters)
+	}
+
+	// "/netlify forms <site> [--public]"
+	if action == "forms" {
+		return p.handleFormsCommand(args, parameters)
+	}
+
+	// "/netlify submissions <form> [--limit N] [--public]" or "/netlify submissions export <form> [--since date]"
+	if action == "submissions" {
+		return p.handleSubmissionsCommand(args, parameters)
+	}
+
+	// "/netlify hooks <site>"
+	if action == "hooks" {
+		return p.handleHooksCommand(args, parameters)
 	}
 
+	// "/netlify subscribe [site]"
 	if action == "subscribe" {
-		return p.handleSubscribeCommand(args)
+		return p.handleSubscribeCommand(args, parameters)
 	}
 
+	// "/netlify unsubscribe [site]"
 	if action == "unsubscribe" {
-		return p.handleUnsubscribeCommand(args)
+		return p.handleUnsubscribeCommand(args, parameters)
 	}
 
 	if action == "subscriptions" {
 		return p.handleSubscriptionsCommand(args)
 	}
 
+	// "/netlify site [site]", "/netlify site create" or "/netlify site delete <site>"
 	if action == "site" {
-		return p.handleSiteCommand(args)
+		if len(parameters) == 1 && parameters[0] == "create" {
+			return p.handleSiteCreateCommand(args)
+		}
+		if len(parameters) == 2 && parameters[0] == "delete" {
+			return p.handleSiteDeleteCommand(args, parameters[1])
+		}
+		return p.handleSiteCommand(args, parameters)
+	}
+
+	// "/netlify status [site]"
+	if action == "status" {
+		return p.handleStatusCommand(args, parameters)
+	}
+
+	// "/netlify search <name, id or domain>"
+	if action == "search" {
+		return p.handleSearchCommand(args, parameters)
 	}
 
 	// "/netlify xyz"
@@ -205,26 +309,13 @@ func (p *Plugin) handleDisconnectCommand(c *plugin.Context, args *model.CommandA
 func (p *Plugin) handleListCommand(args *model.CommandArgs, listInDetail bool) (*model.CommandResponse, *model.AppError) {
 	userID := args.UserId
 
-	// Get the Netlify library client for interacting with netlify api
-	netlifyClient, _ := p.getNetlifyClient()
-
-	// Get Netlify credentials
-	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
-	if err != nil {
-		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
-		return &model.CommandResponse{}, nil
-	}
-
-	// Execute list site func from netlify library
-	listSitesResponse, err := netlifyClient.Operations.ListSites(nil, netlifyCredentials)
+	// Netlify library returns only the first page of sites, so all of them are paged through
+	sites, err := p.listSitesOfUser(userID)
 	if err != nil {
 		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf("Failed to receive sites list from Netlify : %v", err.Error()))
 		return &model.CommandResponse{}, nil
 	}
 
-	// Get all sites from the response payload
-	sites := listSitesResponse.GetPayload()
-
 	// If user has no netlify sites
 	if len(sites) == 0 {
 		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf(":spider_web: You don't seem to have any Netlify sites"))
@@ -354,7 +445,7 @@ func (p *Plugin) handleMeCommand(args *model.CommandArgs) (*model.CommandRespons
 	return &model.CommandResponse{}, nil
 }
 
-func (p *Plugin) handleDeployCommand(args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+func (p *Plugin) handleDeployCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
 	userID := args.UserId
 	actionSecret := p.getConfiguration().EncryptionKey
 
@@ -374,28 +465,17 @@ func (p *Plugin) handleDeployCommand(args *model.CommandArgs) (*model.CommandRes
 
 	p.API.SendEphemeralPost(userID, waitPost)
 
-	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
-	if err != nil {
-		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf(
-			":exclamation: Authentication failed\n"+
-				"*Error : %v*", err.Error()))
-		return &model.CommandResponse{}, nil
-	}
-
-	netlifyClient, _ := p.getNetlifyClient()
-
-	// Execute list site func from netlify library
-	listSitesResponse, err := netlifyClient.Operations.ListSites(nil, netlifyClientCredentials)
+	// "/netlify deploy <site>" lists only the sites matching it
+	siteSearchTerm := strings.Join(parameters, " ")
+	sites, sitesLeftOutNote, err := p.searchSitesForDropdown(userID, siteSearchTerm)
 	if err != nil {
 		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf("Failed to receive sites list from Netlify : %v", err.Error()))
 		return &model.CommandResponse{}, nil
 	}
 
-	sites := listSitesResponse.GetPayload()
-
 	// If user has no netlify sites
 	if len(sites) == 0 {
-		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf(":spider_web: You don't seem to have any Netlify sites"))
+		p.sendMessageFromBot(args.ChannelId, args.UserId, true, describeNoSitesFound(siteSearchTerm))
 		return &model.CommandResponse{}, nil
 	}
 
@@ -430,7 +510,7 @@ func (p *Plugin) handleDeployCommand(args *model.CommandArgs) (*model.CommandRes
 		Title:   "Deploy your Netlify sites",
 		Text:    "Select a site to deploy or redeploy from the list of sites below:\n",
 		Actions: []*model.PostAction{sitesDropdown},
-		Footer:  "Selecting a site from the dropdown will be the final selection. Please be sure before selecting.",
+		Footer:  strings.TrimSpace("Selecting a site from the dropdown will be the final selection. Please be sure before selecting. " + sitesLeftOutNote),
 	}
 
 	deployCommandPost := &model.Post{
@@ -447,7 +527,7 @@ func (p *Plugin) handleDeployCommand(args *model.CommandArgs) (*model.CommandRes
 	return &model.CommandResponse{}, nil
 }
 
-func (p *Plugin) handleRollbackCommand(args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+func (p *Plugin) handleRollbackCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
 	userID := args.UserId
 	actionSecret := p.getConfiguration().EncryptionKey
 
@@ -458,6 +538,23 @@ func (p *Plugin) handleRollbackCommand(args *model.CommandArgs) (*model.CommandR
 		return &model.CommandResponse{}, nil
 	}
 
+	// Optional filters for the rollback history eg. --branch master --context production --since 7d --until 2020-01-31
+	arguments, flags := parseCommandFlags(parameters, "last-good")
+
+	// "/netlify rollback <site> --last-good" restores the newest deploy tagged known-good straight away
+	if flags["last-good"] == "true" {
+		return p.handleRollbackToLastKnownGoodCommand(args, arguments)
+	}
+	for _, dateFlag := range []string{"since", "until"} {
+		if len(flags[dateFlag]) == 0 {
+			continue
+		}
+		if _, err := parseDateFlag(flags[dateFlag]); err != nil {
+			p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf("Invalid value of `--%v` : %v", dateFlag, err.Error()))
+			return &model.CommandResponse{}, nil
+		}
+	}
+
 	// Make command responsive and convey that we are doing work
 	waitPost := &model.Post{
 		UserId:    p.BotUserID,
@@ -467,28 +564,17 @@ func (p *Plugin) handleRollbackCommand(args *model.CommandArgs) (*model.CommandR
 
 	p.API.SendEphemeralPost(userID, waitPost)
 
-	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
-	if err != nil {
-		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf(
-			":exclamation: Authentication failed\n"+
-				"*Error : %v*", err.Error()))
-		return &model.CommandResponse{}, nil
-	}
-
-	netlifyClient, _ := p.getNetlifyClient()
-
-	// Execute list site func from netlify library
-	listSitesResponse, err := netlifyClient.Operations.ListSites(nil, netlifyClientCredentials)
+	// "/netlify rollback <site>" lists only the sites matching it
+	siteSearchTerm := strings.Join(arguments, " ")
+	sites, sitesLeftOutNote, err := p.searchSitesForDropdown(userID, siteSearchTerm)
 	if err != nil {
 		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf("Failed to receive sites list from Netlify : %v", err.Error()))
 		return &model.CommandResponse{}, nil
 	}
 
-	sites := listSitesResponse.GetPayload()
-
 	// If user has no netlify sites
 	if len(sites) == 0 {
-		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf(":spider_web: You don't seem to have any Netlify sites"))
+		p.sendMessageFromBot(args.ChannelId, args.UserId, true, describeNoSitesFound(siteSearchTerm))
 		return &model.CommandResponse{}, nil
 	}
 
@@ -515,6 +601,10 @@ func (p *Plugin) handleRollbackCommand(args *model.CommandArgs) (*model.CommandR
 			URL: fmt.Sprintf("%s/plugins/netlify/command/rollback-builds", *siteURL),
 			Context: map[string]interface{}{
 				"actionSecret": actionSecret,
+				"branch":       flags["branch"],
+				"context":      flags["context"],
+				"since":        flags["since"],
+				"until":        flags["until"],
 			},
 		},
 	}
@@ -523,7 +613,7 @@ func (p *Plugin) handleRollbackCommand(args *model.CommandArgs) (*model.CommandR
 		Title:   "Rollback your Netlify sites to previous versions",
 		Text:    "Select a site which you would like to rollback from the list of sites below:\n",
 		Actions: []*model.PostAction{sitesDropdown},
-		Footer:  "After the selection is made, you will be shown latest of maximum 5 deploys of the selected site to rollback",
+		Footer:  strings.TrimSpace("After the selection is made, you will be shown the successful deploys of the selected site to rollback, page by page. " + sitesLeftOutNote),
 	}
 
 	rollbackCommandPost := &model.Post{
@@ -540,45 +630,182 @@ func (p *Plugin) handleRollbackCommand(args *model.CommandArgs) (*model.CommandR
 	return &model.CommandResponse{}, nil
 }
 
-func (p *Plugin) handleSubscribeCommand(args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+func (p *Plugin) handleRollbackToLastKnownGoodCommand(args *model.CommandArgs, arguments []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
 	channelID := args.ChannelId
+
+	if len(arguments) != 1 {
+		p.sendMessageFromBot(channelID, userID, true, "Please mention the site you want to rollback eg. `/netlify rollback <site> --last-good`")
+		return &model.CommandResponse{}, nil
+	}
+
+	site, err := p.resolveSiteForChange(userID, arguments[0])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	lastKnownGoodDeployID, err := p.getLastKnownGoodDeployID(site.ID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get tags of **%v** site.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	if len(lastKnownGoodDeployID) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":white_flag: No deploy of **%v** site is tagged `%v` yet. Tag one with `/netlify deploy tag %v <deployID> %v`",
+			site.Name, DeployTagKnownGood, site.Name, DeployTagKnownGood))
+		return &model.CommandResponse{}, nil
+	}
+
+	p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+		":two: Preparing to rollback %v site to its last known good deploy %v", site.Name, lastKnownGoodDeployID))
+
+	p.rollbackSiteToDeploy(userID, channelID, site.ID, site.Name, lastKnownGoodDeployID, true)
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleCancelCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
 	userID := args.UserId
+	channelID := args.ChannelId
 	actionSecret := p.getConfiguration().EncryptionKey
+
+	// Check if SiteURL is defined in the app
 	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		p.sendMessageFromBot(channelID, userID, true, "Error! Site URL is not defined in the App")
+		return &model.CommandResponse{}, nil
+	}
 
-	// This command can only be run in a public and private channel
-	if p.isCommandRunFromValidChannel(channelID) == false {
-		p.sendMessageFromBot(channelID, userID, true,
-			fmt.Sprintf("This command can only be run in a private or public channel"),
-		)
+	// Site name or id is required to look for building deploys
+	if len(parameters) != 1 {
+		p.sendMessageFromBot(channelID, userID, true, "Please mention the site whose deploy you want to cancel eg. `/netlify cancel <site>`")
+		return &model.CommandResponse{}, nil
+	}
+
+	site, err := p.resolveSiteForChange(userID, parameters[0])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
 		return &model.CommandResponse{}, nil
 	}
 
 	// Get the Netlify library client for interacting with netlify api
-	netlifyClient, _ := p.getNetlifyClient()
+	netlifyClient, ctx := p.getNetlifyClient()
 
 	// Get Netlify credentials
 	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
 	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	listSiteDeploysParams := &netlifyPlumbingModels.ListSiteDeploysParams{
+		SiteID:  site.ID,
+		Context: ctx,
+	}
+
+	listSiteDeploysResponse, err := netlifyClient.Operations.ListSiteDeploys(listSiteDeploysParams, netlifyCredentials)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get **%v** site recent deploys.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Create an empty array of options we will be using for dropdown
+	var buildingDeploysDropdownOptions []*model.PostActionOptions
+
+	// Take only those deploys which are in progress into consideration
+	for _, deploy := range listSiteDeploysResponse.GetPayload() {
+		if deploy.State != NetlifyEventStateDeployBuilding && deploy.State != NetlifyDeployStateEnqueued {
+			continue
+		}
+
+		deployTitle := deploy.Title
+		if len(deployTitle) == 0 {
+			deployTitle = deploy.ID
+		}
+
+		buildingDeployOption := &model.PostActionOptions{
+			Text:  fmt.Sprintf("%v (%v branch, %v)", deployTitle, deploy.Branch, deploy.State),
+			Value: fmt.Sprintf("%v %v %v", site.ID, site.Name, deploy.ID),
+		}
+		buildingDeploysDropdownOptions = append(buildingDeploysDropdownOptions, buildingDeployOption)
+	}
+
+	// If nothing is building at the moment
+	if len(buildingDeploysDropdownOptions) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: There are no deploys currently building for **%v** site", site.Name))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Construct a dropdown
+	buildingDeploysDropdown := &model.PostAction{
+		Type:     model.POST_ACTION_TYPE_SELECT,
+		Name:     "Select a deploy",
+		Disabled: false,
+		Options:  buildingDeploysDropdownOptions,
+		Integration: &model.PostActionIntegration{
+			URL: fmt.Sprintf("%s/plugins/netlify/command/cancel", *siteURL),
+			Context: map[string]interface{}{
+				"actionSecret": actionSecret,
+			},
+		},
+	}
+
+	cancelCommandInteractiveMessage := &model.SlackAttachment{
+		Title:   fmt.Sprintf("Cancel a deploy of %v site", site.Name),
+		Text:    "Select a deploy which is currently in progress to cancel it:\n",
+		Actions: []*model.PostAction{buildingDeploysDropdown},
+		Footer:  "Selecting a deploy from the dropdown will be the final selection. Please be sure before selecting.",
+	}
+
+	cancelCommandPost := &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": []*model.SlackAttachment{cancelCommandInteractiveMessage},
+		},
+	}
+
+	// Present the user with the deploys dropdown
+	p.API.SendEphemeralPost(userID, cancelCommandPost)
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleSubscribeCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	channelID := args.ChannelId
+	userID := args.UserId
+	actionSecret := p.getConfiguration().EncryptionKey
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+
+	// This command can only be run in a public and private channel
+	if p.isCommandRunFromValidChannel(channelID) == false {
 		p.sendMessageFromBot(channelID, userID, true,
-			fmt.Sprintf("Authentication failed : %v", err.Error()),
+			fmt.Sprintf("This command can only be run in a private or public channel"),
 		)
 		return &model.CommandResponse{}, nil
 	}
 
-	// Execute list site func from netlify library
-	listSitesResponse, err := netlifyClient.Operations.ListSites(nil, netlifyCredentials)
+	// "/netlify subscribe <site>" lists only the sites matching it
+	siteSearchTerm := strings.Join(parameters, " ")
+	sites, sitesLeftOutNote, err := p.searchSitesForDropdown(userID, siteSearchTerm)
 	if err != nil {
-		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf("Failed to receive sites list from Netlify : %v", err.Error()))
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Failed to receive sites list from Netlify : %v", err.Error()))
 		return &model.CommandResponse{}, nil
 	}
 
-	// Get all sites from the response payload
-	sites := listSitesResponse.GetPayload()
-
 	// If user has no netlify sites
 	if len(sites) == 0 {
-		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf(":spider_web: You don't seem to have any Netlify sites"))
+		p.sendMessageFromBot(channelID, userID, true, describeNoSitesFound(siteSearchTerm))
 		return &model.CommandResponse{}, nil
 	}
 
@@ -614,7 +841,7 @@ func (p *Plugin) handleSubscribeCommand(args *model.CommandArgs) (*model.Command
 		Title:   "Select a site you want to subscribe build notifications for",
 		Text:    "Selecting a site will subscribe current channel for build start, success and fail notifications.\n",
 		Actions: []*model.PostAction{sitesDropdown},
-		Footer:  "If however you don't wish to subscribe, hit the (x) cross icon on the right to dismiss this message",
+		Footer:  strings.TrimSpace("If however you don't wish to subscribe, hit the (x) cross icon on the right to dismiss this message. " + sitesLeftOutNote),
 	}
 
 	subscribeCommandPost := &model.Post{
@@ -631,7 +858,7 @@ func (p *Plugin) handleSubscribeCommand(args *model.CommandArgs) (*model.Command
 	return &model.CommandResponse{}, nil
 }
 
-func (p *Plugin) handleUnsubscribeCommand(args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+func (p *Plugin) handleUnsubscribeCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
 	channelID := args.ChannelId
 	userID := args.UserId
 
@@ -643,28 +870,35 @@ func (p *Plugin) handleUnsubscribeCommand(args *model.CommandArgs) (*model.Comma
 		return &model.CommandResponse{}, nil
 	}
 
-	// Get the Netlify library client for interacting with netlify api
-	netlifyClient, _ := p.getNetlifyClient()
+	// "/netlify unsubscribe <site>" unsubscribes the channel from just that site
+	if len(parameters) != 0 {
+		site, err := p.resolveSiteForChange(userID, strings.Join(parameters, " "))
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Failed to find the site\n"+
+					"*Error : %v*", err.Error()))
+			return &model.CommandResponse{}, nil
+		}
 
-	// Get Netlify credentials
-	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
-	if err != nil {
-		p.sendMessageFromBot(channelID, userID, true,
-			fmt.Sprintf("Authentication failed : %v", err.Error()),
-		)
+		err = p.snapWebhookSubscriptionForSite(site.ID, channelID)
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Could not unsubscribe channel with %v site\n"+
+					"*Error : %v*", site.Name, err.Error()))
+			return &model.CommandResponse{}, nil
+		}
+
+		p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(
+			":no_bell: Successfully unsubscribed this channel for build notifications from **%v** site.", site.Name))
 		return &model.CommandResponse{}, nil
 	}
 
-	// Execute list site func from netlify library
-	listSitesResponse, err := netlifyClient.Operations.ListSites(nil, netlifyCredentials)
+	sites, err := p.listSitesOfUser(userID)
 	if err != nil {
 		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf("Failed to receive sites list from Netlify : %v", err.Error()))
 		return &model.CommandResponse{}, nil
 	}
 
-	// Get all sites from the response payload
-	sites := listSitesResponse.GetPayload()
-
 	// If user has no netlify sites
 	if len(sites) == 0 {
 		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf(":spider_web: You don't seem to have any Netlify sites"))
@@ -715,31 +949,16 @@ func (p *Plugin) handleSubscriptionsCommand(args *model.CommandArgs) (*model.Com
 		return &model.CommandResponse{}, nil
 	}
 
-	// Get the Netlify library client for interacting with netlify api
-	netlifyClient, _ := p.getNetlifyClient()
-
-	// Get Netlify credentials
-	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
-	if err != nil {
-		p.sendMessageFromBot(channelID, userID, true,
-			fmt.Sprintf("Authentication failed : %v", err.Error()),
-		)
-		return &model.CommandResponse{}, nil
-	}
-
 	// Show message stating that we are working on it
 	p.sendMessageFromBot(channelID, userID, true, ":hourglass_flowing_sand: Please wait while we retrieve all sites subscribed to this channel")
 
-	// Execute list site func from netlify library
-	listSitesResponse, err := netlifyClient.Operations.ListSites(nil, netlifyCredentials)
+	// Netlify library returns only the first page of sites, so all of them are paged through
+	sites, err := p.listSitesOfUser(userID)
 	if err != nil {
 		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf("Failed to receive sites list from Netlify : %v", err.Error()))
 		return &model.CommandResponse{}, nil
 	}
 
-	// Get all sites from the response payload
-	sites := listSitesResponse.GetPayload()
-
 	// If user has no netlify sites
 	if len(sites) == 0 {
 		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf(":spider_web: You don't seem to have any Netlify sites"))
@@ -801,37 +1020,23 @@ func (p *Plugin) handleSubscriptionsCommand(args *model.CommandArgs) (*model.Com
 	return &model.CommandResponse{}, nil
 }
 
-func (p *Plugin) handleSiteCommand(args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+func (p *Plugin) handleSiteCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
 	channelID := args.ChannelId
 	userID := args.UserId
 	actionSecret := p.getConfiguration().EncryptionKey
 	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
 
-	// Get the Netlify library client for interacting with netlify api
-	netlifyClient, _ := p.getNetlifyClient()
-
-	// Get Netlify credentials
-	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
-	if err != nil {
-		p.sendMessageFromBot(channelID, userID, true,
-			fmt.Sprintf("Authentication failed : %v", err.Error()),
-		)
-		return &model.CommandResponse{}, nil
-	}
-
-	// Execute list site func from netlify library
-	listSitesResponse, err := netlifyClient.Operations.ListSites(nil, netlifyCredentials)
+	// "/netlify site <site>" lists only the sites matching it
+	siteSearchTerm := strings.Join(parameters, " ")
+	sites, sitesLeftOutNote, err := p.searchSitesForDropdown(userID, siteSearchTerm)
 	if err != nil {
-		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf("Failed to receive sites list from Netlify : %v", err.Error()))
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Failed to receive sites list from Netlify : %v", err.Error()))
 		return &model.CommandResponse{}, nil
 	}
 
-	// Get all sites from the response payload
-	sites := listSitesResponse.GetPayload()
-
 	// If user has no netlify sites
 	if len(sites) == 0 {
-		p.sendMessageFromBot(args.ChannelId, args.UserId, true, fmt.Sprintf(":spider_web: You don't seem to have any Netlify sites"))
+		p.sendMessageFromBot(channelID, userID, true, describeNoSitesFound(siteSearchTerm))
 		return &model.CommandResponse{}, nil
 	}
 
@@ -866,6 +1071,7 @@ func (p *Plugin) handleSiteCommand(args *model.CommandArgs) (*model.CommandRespo
 		Pretext: "View information of Netlify site",
 		Title:   "Select a site you want to view more information about.",
 		Actions: []*model.PostAction{sitesDropdown},
+		Footer:  sitesLeftOutNote,
 	}
 
 	siteCommandPost := &model.Post{
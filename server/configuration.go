@@ -24,6 +24,13 @@ type configuration struct {
 	NetlifyOAuthSecret   string
 	EncryptionKey        string
 	WebhookSecret        string
+
+	EnableCertificateWatchdog    bool
+	CertificateExpiryWarningDays string
+
+	AdminRoles string
+
+	UsageAlertChannelID string
 }
 
 // Clone shallow copies the configuration. Your implementation may require a deep copy if
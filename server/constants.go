@@ -1,5 +1,7 @@
 package main
 
+import "time"
+
 type ctxKey int
 
 // Netlify Library client related
@@ -16,6 +18,27 @@ const (
 	// NetlifyAuthTokenKVIdentifier is used to in suffix with userID to identify key in KV store
 	NetlifyAuthTokenKVIdentifier            string = "_netlifyToken"
 	NetlifyWebhookSubscriptionsKVIdentifier string = "_webhook"
+
+	// NetlifyDeployLockKVIdentifier is used in suffix with siteID to store who locked the site and why
+	NetlifyDeployLockKVIdentifier string = "_lock"
+
+	// NetlifyDeployTagsKVIdentifier is used in suffix with siteID to store tags given to its deploys
+	NetlifyDeployTagsKVIdentifier string = "_deployTags"
+
+	// NetlifyAuditRecordsKVIdentifier is used in suffix with siteID to store changes made to the site from Mattermost
+	NetlifyAuditRecordsKVIdentifier string = "_audit"
+
+	// NetlifyTeamAuditRecordsKVIdentifier is used in suffix with accountID to store changes made to the team account from Mattermost
+	NetlifyTeamAuditRecordsKVIdentifier string = "_teamAudit"
+
+	// NetlifyCertificateWatchKVIdentifier is used in suffix with siteID to store warnings given for its certificate
+	NetlifyCertificateWatchKVIdentifier string = "_certificateWatch"
+
+	// NetlifyUsageAlertsKVIdentifier is used in suffix with accountID to store usage thresholds already alerted in the billing period
+	NetlifyUsageAlertsKVIdentifier string = "_usageAlerts"
+
+	// NetlifyJobLockKVIdentifier is used in suffix with name of a background job to lock it to a single server of the cluster
+	NetlifyJobLockKVIdentifier string = "_jobLock"
 )
 
 // Netlify specific constants
@@ -34,6 +57,9 @@ const (
 
 	// NetlifyDateLayout is the date format returned by Netlify api for dates
 	NetlifyDateLayout string = "2006-01-02T15:04:05.000Z"
+
+	// CommandFlagDateLayout is the date format users can pass in command flags like --since
+	CommandFlagDateLayout string = "2006-01-02"
 )
 
 // Netlify Build hook related
@@ -43,6 +69,137 @@ const (
 
 	// MattermostNetlifyBuildHookMessage will be message of all build hook deploys from mattermost
 	MattermostNetlifyBuildHookMessage string = "triggered by Netlify Bot from Mattermost"
+
+	// BuildHookURLVisibleCharacters is the number of characters of the secret in a build hook URL left visible when it is redacted
+	BuildHookURLVisibleCharacters int = 4
+)
+
+// Rollback related
+const (
+	// DeployTagKnownGood is the tag which marks a deploy safe to rollback to with --last-good
+	DeployTagKnownGood string = "known-good"
+
+	// RollbackDeploysPerPage is the number of deploys shown on every page of rollback history
+	RollbackDeploysPerPage int = 10
+
+	// RollbackDeploysFetchedPerPage is the number of deploys fetched from Netlify at once while filling a page of rollback history
+	RollbackDeploysFetchedPerPage int = 100
+
+	// RollbackVerificationTimeout is the maximum time we wait for a restored deploy to get published
+	RollbackVerificationTimeout time.Duration = 2 * time.Minute
+
+	// RollbackVerificationInterval is the time between two checks of the published deploy of a site
+	RollbackVerificationInterval time.Duration = 5 * time.Second
+
+	// RollbackUndoWindow is the time after a rollback during which it can be undone from its post
+	RollbackUndoWindow time.Duration = 30 * time.Minute
+)
+
+// Deploys command related
+const (
+	// DeployHistoryPerPage is the number of deploys shown on a single page of deploy history
+	DeployHistoryPerPage int = 10
+
+	// DeployHistoryExportPerPage is the number of deploys fetched at once while exporting deploy history
+	DeployHistoryExportPerPage int = 100
+
+	// DeployHistoryExportLimit is the maximum number of deploys exported when no limit is given
+	DeployHistoryExportLimit int = 1000
+)
+
+// Env command related
+const (
+	// EnvironmentVariableMaskedValue is shown in place of value of an environment variable until it is revealed
+	EnvironmentVariableMaskedValue string = "`••••••••`"
+
+	// AuditRecordsPerSiteLimit is the maximum number of audit records kept for a site, or for a team account
+	AuditRecordsPerSiteLimit int = 500
+)
+
+// Site search related
+const (
+	// SitesPerPage is the number of sites fetched at once while listing all the sites of a user
+	SitesPerPage int = 100
+
+	// SiteSearchResultsLimit is the maximum number of sites shown by search command
+	SiteSearchResultsLimit int = 20
+
+	// SiteResolveCandidatesLimit is the number of sites named when a site passed in a command matches more than one
+	SiteResolveCandidatesLimit int = 5
+
+	// SiteDropdownOptionsLimit is the maximum number of sites put in a dropdown, as Mattermost doesn't show more options
+	SiteDropdownOptionsLimit int = 100
+)
+
+// Audit command related
+const (
+	// AccountAuditLogPerPage is the number of audit events shown on every page of audit log of an account
+	AccountAuditLogPerPage int = 20
+
+	// AccountAuditEventsFetchedPerPage is the number of audit events fetched from Netlify at once while filling a page of audit log
+	AccountAuditEventsFetchedPerPage int = 100
+)
+
+// Usage command related
+const (
+	// Limits of a plan which usage of a team account is shown against
+	AccountUsageLimitBandwidth    string = "Bandwidth"
+	AccountUsageLimitBuildMinutes string = "Build minutes"
+)
+
+// AccountUsageAlertThresholds are the percentages of a plan limit at which the configured channel is alerted, lowest first
+var AccountUsageAlertThresholds = []int{80, 100}
+
+// Functions command related
+const (
+	// FunctionsPreviousDeploySearchLimit is the number of recent successful deploys looked into for the previous deploy
+	FunctionsPreviousDeploySearchLimit int = 100
+)
+
+// Submissions command related
+const (
+	// SubmissionsDefaultLimit is the number of recent submissions shown when no limit is given
+	SubmissionsDefaultLimit int = 10
+
+	// SubmissionsPerPage is the maximum number of submissions Netlify returns at once
+	SubmissionsPerPage int = 100
+)
+
+// Background jobs related
+const (
+	// ClusterJobLockMargin is subtracted from the interval a server locks a background job for
+	ClusterJobLockMargin time.Duration = time.Minute
+
+	// KVListPerPage is the number of keys fetched at once while going over the KV store
+	KVListPerPage int = 1000
+
+	// CertificateWatchdogJobName identifies the job checking certificates of subscribed sites
+	CertificateWatchdogJobName string = "certificateWatchdog"
+
+	// CertificateWatchdogInterval is the time between two checks of certificates of subscribed sites
+	CertificateWatchdogInterval time.Duration = 6 * time.Hour
+
+	// UsageWatchdogJobName identifies the job checking usage of team accounts against their plan limits
+	UsageWatchdogJobName string = "usageWatchdog"
+
+	// UsageWatchdogInterval is the time between two checks of usage of team accounts
+	UsageWatchdogInterval time.Duration = time.Hour
+
+	// States of a site certificate which are not failures
+	NetlifyCertificateStateIssued  string = "issued"
+	NetlifyCertificateStatePending string = "pending"
+)
+
+// Deploy status command related
+const (
+	// SiteStatusDeploysPerPage is the number of most recent deploys looked into for building, enqueued or failed ones
+	SiteStatusDeploysPerPage int = 20
+
+	// SiteStatusRefreshInterval is the time between two refreshes of the status post while a deploy is in progress
+	SiteStatusRefreshInterval time.Duration = 10 * time.Second
+
+	// SiteStatusRefreshTimeout is the maximum time the status post keeps refreshing itself
+	SiteStatusRefreshTimeout time.Duration = 30 * time.Minute
 )
 
 // SuccessfullyNetlifyConnectedMessage is posted when /connect command is executed and completed
@@ -60,8 +217,73 @@ const (
 
 	// MarkdownDeployListTableHeader is table rendered in markdown to show info regarding site build
 	MarkdownDeployListTableHeader string = `
-| Sequence |   SHA  | Deployed at |  Deploy ID  |
-|:--------:|:-------|------------:|-------------|`
+| Commit title |   SHA  | Author | Branch | Context | Published at |  Deploy ID  | Tags |
+|:-------------|:-------|:-------|:-------|:--------|-------------:|-------------|------|`
+
+	// MarkdownDeployHistoryTableHeader is table rendered in markdown to show deploys of a site irrespective of their state
+	MarkdownDeployHistoryTableHeader string = `
+| State | Commit title |   SHA  | Author | Branch | Context | Created at | Published at | Build time |  Deploy ID  | Tags |
+|:------|:-------------|:-------|:-------|:-------|:--------|-----------:|-------------:|-----------:|-------------|------|`
+
+	// MarkdownDeployStatusTableHeader is table rendered in markdown to show deploys which are not yet published
+	MarkdownDeployStatusTableHeader string = `
+| State | Branch | Commit | Started at | Elapsed | Deploy ID |
+|:------|:-------|:-------|-----------:|--------:|-----------|`
+
+	// MarkdownEnvironmentVariableTableHeader is table rendered in markdown to show environment variables of a site
+	MarkdownEnvironmentVariableTableHeader string = `
+| Key | Value |
+|:----|:------|`
+
+	// MarkdownDNSZoneTableHeader is table rendered in markdown to show DNS zones
+	MarkdownDNSZoneTableHeader string = `
+| Zone | Zone ID |
+|:-----|:--------|`
+
+	// MarkdownDNSRecordTableHeader is table rendered in markdown to show records of a DNS zone
+	MarkdownDNSRecordTableHeader string = `
+| Type | Hostname | Value | TTL | Priority | Record ID |
+|:-----|:---------|:------|----:|---------:|-----------|`
+
+	// MarkdownFormTableHeader is table rendered in markdown to show forms of a site
+	MarkdownFormTableHeader string = `
+| Form | Submissions | Paths | Created at | Form ID |
+|:-----|------------:|:------|-----------:|---------|`
+
+	// MarkdownSubmissionTableHeader is table rendered in markdown to show submissions of a form
+	MarkdownSubmissionTableHeader string = `
+| # | Submitted at | Name | Email | Summary | Submission ID |
+|--:|-------------:|:-----|:------|:--------|---------------|`
+
+	// MarkdownFunctionTableHeader is table rendered in markdown to show functions of a deploy
+	MarkdownFunctionTableHeader string = `
+| Function | Runtime | Size | Change |
+|:---------|:--------|-----:|:-------|`
+
+	// MarkdownTeamMemberTableHeader is table rendered in markdown to show members of a team account
+	MarkdownTeamMemberTableHeader string = `
+| Name | Email | Role | Member ID |
+|:-----|:------|:-----|-----------|`
+
+	// MarkdownAccountAuditLogTableHeader is table rendered in markdown to show audit events of a team account
+	MarkdownAccountAuditLogTableHeader string = `
+| Time | Actor | Action | Site |
+|-----:|:------|:-------|:-----|`
+
+	// MarkdownAccountUsageTableHeader is table rendered in markdown to show usage of a team account against its plan limits
+	MarkdownAccountUsageTableHeader string = `
+| Limit | Used | Included | Usage | Billing period |
+|:------|-----:|---------:|------:|:---------------|`
+
+	// MarkdownSiteSearchTableHeader is table rendered in markdown to show sites matching a search term
+	MarkdownSiteSearchTableHeader string = `
+| Site | Matched on | URL | Site ID |
+|:-----|:-----------|:----|:--------|`
+
+	// MarkdownSiteSettingsDiffTableHeader is table rendered in markdown to show settings of a site which were changed
+	MarkdownSiteSettingsDiffTableHeader string = `
+| Setting | Before | After |
+|:--------|:-------|:------|`
 
 	MarkdownSubscriptionTableHeader string = `
 | Site | URL | Status |
@@ -78,6 +300,37 @@ const (
 	ActionDisconnectPlugin = "ActionDisconnectPlugin"
 	// ActionCancel can be used in any Post action to identify cancel action
 	ActionCancel = "ActionCancel"
+	// ActionDeleteDNSRecord is used in Post action to identify confirmation of deleting a DNS record
+	ActionDeleteDNSRecord = "ActionDeleteDNSRecord"
+	// ActionProvisionCertificate is used in Post action to identify provisioning a certificate for a site
+	ActionProvisionCertificate = "ActionProvisionCertificate"
+	// ActionForceHTTPS is used in Post action to identify forcing HTTPS on a site
+	ActionForceHTTPS = "ActionForceHTTPS"
+	// ActionMarkSubmissionSpam is used in Post action to identify marking a form submission as spam
+	ActionMarkSubmissionSpam = "ActionMarkSubmissionSpam"
+	// ActionMarkSubmissionHam is used in Post action to identify marking a form submission as ham
+	ActionMarkSubmissionHam = "ActionMarkSubmissionHam"
+	// ActionDeleteSubmission is used in Post action to identify deleting a form submission, which is confirmed before it is deleted
+	ActionDeleteSubmission = "ActionDeleteSubmission"
+	// ActionRevealBuildHookURL is used in Post action to identify showing URL of a build hook to the user
+	ActionRevealBuildHookURL = "ActionRevealBuildHookURL"
+	// ActionDeleteBuildHook is used in Post action to identify deleting a build hook, which is confirmed before it is deleted
+	ActionDeleteBuildHook = "ActionDeleteBuildHook"
+	// ActionEnableHook is used in Post action to identify enabling a notification hook
+	ActionEnableHook = "ActionEnableHook"
+	// ActionDisableHook is used in Post action to identify disabling a notification hook
+	ActionDisableHook = "ActionDisableHook"
+	// ActionDeleteHook is used in Post action to identify deleting a notification hook, which is confirmed before it is deleted
+	ActionDeleteHook = "ActionDeleteHook"
+)
+
+const (
+	// DialogSiteCreate is the callback id of the interactive dialog for creating a site
+	DialogSiteCreate = "DialogSiteCreate"
+	// DialogSiteDelete is the callback id of the interactive dialog for deleting a site
+	DialogSiteDelete = "DialogSiteDelete"
+	// DialogSiteSettings is the callback id of the interactive dialog for editing settings of a site
+	DialogSiteSettings = "DialogSiteSettings"
 )
 
 // Netlify Notification Hook events types
@@ -112,11 +365,21 @@ const (
 	NetlifyEventStateDeployFailed   string = "error"
 )
 
+// States of a Netlify deploy which is yet to finish
+const (
+	NetlifyDeployStateEnqueued   string = "enqueued"
+	NetlifyDeployStateNew        string = "new"
+	NetlifyDeployStateUploading  string = "uploading"
+	NetlifyDeployStateUploaded   string = "uploaded"
+	NetlifyDeployStateProcessing string = "processing"
+)
+
 // Types of Netlify Hooks
 const (
-	NetlifyHookTypeSlack string = "slack"
-	NetlifyHookTypeURL   string = "url"
-	NetlifyHookTypeEmail string = "email"
+	NetlifyHookTypeSlack  string = "slack"
+	NetlifyHookTypeURL    string = "url"
+	NetlifyHookTypeEmail  string = "email"
+	NetlifyHookTypeGithub string = "github"
 )
 
 // Header information inside of incoming webhook
@@ -131,12 +394,35 @@ const HelpPost string = `
 * /netlify **disconnect** - Disconnect your Mattermost account from your Netlify account All notifications are also unsubscribed from all channels.
 * /netlify **list** - It tabulates all the sites information of Netlify account. It lists name, url, custom domain, repository, deployed branch, managed by team, last updated of the site.
 * /netlify **list id** - This is usually a precursor command which you will be using to obtain site ids of you netlify hosted sites. It tabulates your sites along with its ids.
-* /netlify **deploy** - Triggers a rebuild or build for your Netlify site.
-* /netlify **rollback** - Facilitate to quick rollback to a previous stable state of your Netlify site.
-* /netlify **subscribe** - Subscribes the channel to receive build notifications from your Netlify site(s).
-* /netlify **unsubscribe** - Unsubscribes the channel from build notifications from all of your Netlify site(s).
+* /netlify **deploy** *[site]* - Triggers a rebuild or build for your Netlify site.
+* /netlify **deploy tag** *<site> <deployID> <tag> [note]* - Tags a deploy of your Netlify site eg. as known-good.
+* /netlify **rollback** *[site] [--branch b] [--context c] [--since 7d] [--until 2020-01-31]* - Facilitate to quick rollback to a previous stable state of your Netlify site.
+* /netlify **rollback** *<site> --last-good* - Rollbacks your Netlify site to the newest deploy tagged known-good.
+* /netlify **deploys** *<site> [--branch b] [--state error] [--since 7d] [--limit N] [--export csv]* - Shows deploy history of your Netlify site, optionally exported as a csv file.
+* /netlify **buildhooks** *<site>* or *create <site> <branch> <title>* or *delete <site> <hookID>* - Lists, creates and deletes build hooks of your Netlify site.
+* /netlify **cancel** *<site>* - Cancels a deploy of your Netlify site which is currently building.
+* /netlify **lock** *<site> [reason]* - Locks production publishing of your Netlify site to its currently published deploy.
+* /netlify **unlock** *<site>* - Unlocks production publishing of your Netlify site so new deploys get published again.
+* /netlify **env** *list|get|set|unset <site> [key] [value] [--redeploy]* - Manages build environment variables of your Netlify site.
+* /netlify **domain** *set|add-alias|remove-alias <site> <domain>* - Changes custom domain or domain aliases of your Netlify site.
+* /netlify **ssl** *<site>* - Shows SSL certificate of your Netlify site, with options to provision a certificate and force HTTPS.
+* /netlify **dns** *zones|records|add|delete* - Lists DNS zones and manages their records with Netlify DNS.
+* /netlify **functions** *<site> [deployID]* - Lists functions of the published or given deploy of your Netlify site, flagging those added or removed since the previous deploy.
+* /netlify **forms** *<site> [--public]* - Lists forms of your Netlify site with their submission counts.
+* /netlify **submissions** *<form> [--limit N] [--public]* - Shows recent submissions of a form, with options to mark them as spam or ham or delete them.
+* /netlify **submissions export** *<form> [--since 2020-01-31]* - Uploads all submissions of a form to the channel as a csv file.
+* /netlify **hooks** *<site>* - Lists notification hooks of every type on your Netlify site, with options to enable, disable or delete them.
+* /netlify **subscribe** *[site]* - Subscribes the channel to receive build notifications from your Netlify site(s).
+* /netlify **unsubscribe** *[site]* - Unsubscribes the channel from build notifications from the site, or from all of your Netlify site(s).
 * /netlify **subscriptions** - Lists out all your Netlify site(s) subscribed with the channel.
-* /netlify **site** - Shows in-depth information of your Netlify site.
+* /netlify **site** *[site]* - Shows in-depth information of your Netlify site, with an option to edit its asset optimization and build settings.
+* /netlify **site create** - Opens a dialog to create a new Netlify site, optionally linked to a repository.
+* /netlify **site delete** *<site>* - Deletes your Netlify site once its name is typed to confirm, for admins only.
+* /netlify **status** *[site]* - Shows the published deploy along with building, enqueued and failed deploys of your Netlify site.
+* /netlify **search** *<term>* - Finds your Netlify sites by part of their name, id, custom domain or domain aliases.
+* /netlify **team** *members [account]* or *invite <email> [account] [--role r]* or *remove <email> [account]* - Lists members of your Netlify team, admins can invite and remove them.
+* /netlify **audit** *[account] [--since 7d] [--actor name] [--site name]* - Shows audit log of your Netlify team, optionally filtered by actor and site.
+* /netlify **usage** *[account]* - Shows bandwidth and build minutes used by your Netlify team against its plan limits.
 * /netlify **me** - This commands show revelant information of the Netlify account connected to Mattermost.
 * /netlify **help** - Shows help with plugin commands and features.
 `
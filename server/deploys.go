@@ -0,0 +1,419 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+func (p *Plugin) handleDeploysCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	// Eg. /netlify deploys <site> --branch master --state error --since 7d --limit 50 --export csv
+	arguments, flags := parseCommandFlags(parameters)
+	if len(arguments) != 1 {
+		p.sendMessageFromBot(channelID, userID, true,
+			"Please mention the site eg. `/netlify deploys <site> [--branch b] [--state error] [--since 7d] [--limit N] [--export csv]`")
+		return &model.CommandResponse{}, nil
+	}
+
+	if since, ok := flags["since"]; ok {
+		if _, err := parseDateFlag(since); err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":exclamation: Invalid value of --since flag. %v", err.Error()))
+			return &model.CommandResponse{}, nil
+		}
+	}
+
+	limit := 0
+	if limitFlag, ok := flags["limit"]; ok {
+		limitParsed, err := strconv.Atoi(limitFlag)
+		if err != nil || limitParsed <= 0 {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":exclamation: Invalid value of --limit flag, %v is not a positive number", limitFlag))
+			return &model.CommandResponse{}, nil
+		}
+		limit = limitParsed
+	}
+
+	export, isExport := flags["export"]
+	if isExport == true && export != "csv" {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":exclamation: Deploys can only be exported as csv, %v is not supported", export))
+		return &model.CommandResponse{}, nil
+	}
+
+	site, err := p.resolveSite(userID, arguments[0])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	if isExport == true {
+		p.exportDeployHistoryOfSite(userID, channelID, site.ID, site.Name, flags["branch"], flags["state"], flags["since"], limit)
+		return &model.CommandResponse{}, nil
+	}
+
+	deployHistoryPost, err := p.getDeployHistoryPost(userID, channelID, site.ID, site.Name, 1, flags["branch"], flags["state"], flags["since"], limit)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get **%v** site deploys.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	p.API.SendEphemeralPost(userID, deployHistoryPost)
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleDeploysCommandResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	siteID, _ := intergrationResponseFromCommand.Context["siteID"].(string)
+	siteName, _ := intergrationResponseFromCommand.Context["siteName"].(string)
+	branchFilter, _ := intergrationResponseFromCommand.Context["branch"].(string)
+	stateFilter, _ := intergrationResponseFromCommand.Context["state"].(string)
+	sinceFilter, _ := intergrationResponseFromCommand.Context["since"].(string)
+	pagePassed, _ := intergrationResponseFromCommand.Context["page"].(string)
+	limitPassed, _ := intergrationResponseFromCommand.Context["limit"].(string)
+
+	page, err := strconv.Atoi(pagePassed)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(limitPassed)
+
+	deployHistoryPost, err := p.getDeployHistoryPost(userID, channelID, siteID, siteName, page, branchFilter, stateFilter, sinceFilter, limit)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get **%v** site deploys.\n"+
+				"*Error : %v*", siteName, err.Error()))
+		return
+	}
+
+	// Replace the previous page with the one asked for
+	deployHistoryPost.Id = intergrationResponseFromCommand.PostId
+	p.API.UpdateEphemeralPost(userID, deployHistoryPost)
+}
+
+// getDeployHistoryPost returns a post with a single page of deploys of the site in a table, along with buttons to move across pages.
+// A limit of 0 means there is no limit on number of deploys which can be paged through.
+func (p *Plugin) getDeployHistoryPost(userID, channelID, siteID, siteName string, page int, branch, state, since string, limit int) (*model.Post, error) {
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		return nil, fmt.Errorf("Site URL is not defined in the App")
+	}
+
+	deploys, hasOlderDeploys, err := p.listFilteredSiteDeploys(userID, siteID, page, DeployHistoryPerPage, branch, state, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deploys beyond the limit are left out of the last page
+	if limit != 0 {
+		deploysBeforePage := (page - 1) * DeployHistoryPerPage
+		if deploysBeforePage+len(deploys) >= limit {
+			hasOlderDeploys = false
+		}
+		if deploysBeforePage+len(deploys) > limit {
+			deploys = deploys[:limit-deploysBeforePage]
+		}
+	}
+
+	// Tags given to the deploys from Mattermost
+	deployTags, _ := p.getDeployTagsForSite(siteID)
+
+	// Create a table with just the header, rows will fill up in the loop
+	var deployMarkdownTable string = MarkdownDeployHistoryTableHeader
+
+	for _, deploy := range deploys {
+		var deployTableRow string = fmt.Sprintf("| %v %v | %v | %v | %v | %v | %v | %v | %v | %v | %v | %v |",
+			getDeployStateIcon(deploy.State), deploy.State, describeDeployTitle(deploy.Title), describeDeploySHA(deploy.CommitRef),
+			describeDeployAuthor(deploy.Committer), deploy.Branch, deploy.Context, formatNetlifyDate(deploy.CreatedAt),
+			formatNetlifyDate(deploy.PublishedAt), describeDeployTime(deploy.DeployTime), deploy.ID, describeDeployTags(deployTags[deploy.ID]))
+		deployMarkdownTable = fmt.Sprintf("%v\n%v", deployMarkdownTable, deployTableRow)
+	}
+
+	// Context shared by the older and newer buttons, so filters stay the same across pages
+	pageButtonContext := func(toPage int) map[string]interface{} {
+		return map[string]interface{}{
+			"actionSecret": p.getConfiguration().EncryptionKey,
+			"siteID":       siteID,
+			"siteName":     siteName,
+			"page":         strconv.Itoa(toPage),
+			"branch":       branch,
+			"state":        state,
+			"since":        since,
+			"limit":        strconv.Itoa(limit),
+		}
+	}
+
+	var deployHistoryActions []*model.PostAction
+
+	if hasOlderDeploys == true {
+		deployHistoryActions = append(deployHistoryActions, &model.PostAction{
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Name: "Older",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("%s/plugins/netlify/command/deploys", *siteURL),
+				Context: pageButtonContext(page + 1),
+			},
+		})
+	}
+
+	if page > 1 {
+		deployHistoryActions = append(deployHistoryActions, &model.PostAction{
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Name: "Newer",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("%s/plugins/netlify/command/deploys", *siteURL),
+				Context: pageButtonContext(page - 1),
+			},
+		})
+	}
+
+	deployHistoryText := deployMarkdownTable
+	if len(deploys) == 0 {
+		deployHistoryText = "*No deploys on this page matched the filters*"
+	}
+
+	deployHistoryAttachment := &model.SlackAttachment{
+		Title:   fmt.Sprintf("Deploy history of %v site", siteName),
+		Text:    deployHistoryText,
+		Actions: deployHistoryActions,
+		Footer:  fmt.Sprintf("Page %v of deploys%v", page, describeDeployHistoryFilters(branch, state, since, limit)),
+	}
+
+	return &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": []*model.SlackAttachment{deployHistoryAttachment},
+		},
+	}, nil
+}
+
+// exportDeployHistoryOfSite pages through all the deploys of the site matching the filters
+// and uploads them as a csv file to the channel
+func (p *Plugin) exportDeployHistoryOfSite(userID, channelID, siteID, siteName, branch, state, since string, limit int) {
+	if limit == 0 {
+		limit = DeployHistoryExportLimit
+	}
+
+	var deploys []*NetlifyDeploy
+	for page := 1; len(deploys) < limit; page++ {
+		deploysOfPage, hasOlderDeploys, err := p.listFilteredSiteDeploys(userID, siteID, page, DeployHistoryExportPerPage, branch, state, since)
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Failed to get **%v** site deploys.\n"+
+					"*Error : %v*", siteName, err.Error()))
+			return
+		}
+
+		deploys = append(deploys, deploysOfPage...)
+
+		if hasOlderDeploys == false {
+			break
+		}
+	}
+
+	if len(deploys) > limit {
+		deploys = deploys[:limit]
+	}
+
+	if len(deploys) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: There are no deploys of **%v** site matching the filters", siteName))
+		return
+	}
+
+	// Tags given to the deploys from Mattermost
+	deployTags, _ := p.getDeployTagsForSite(siteID)
+
+	var deploysInCSV bytes.Buffer
+	csvWriter := csv.NewWriter(&deploysInCSV)
+
+	csvWriter.Write([]string{"Deploy ID", "State", "Commit title", "SHA", "Author", "Branch", "Context",
+		"Created at", "Published at", "Build time (seconds)", "Error message", "Deploy URL", "Tags"})
+
+	for _, deploy := range deploys {
+		var tags []string
+		for _, deployTag := range deployTags[deploy.ID] {
+			tags = append(tags, deployTag.Tag)
+		}
+
+		csvWriter.Write(escapeCSVRow([]string{deploy.ID, deploy.State, strings.Split(deploy.Title, "\n")[0], deploy.CommitRef, deploy.Committer,
+			deploy.Branch, deploy.Context, deploy.CreatedAt, deploy.PublishedAt, strconv.FormatInt(deploy.DeployTime, 10),
+			deploy.ErrorMessage, deploy.DeploySslURL, strings.Join(tags, " ")}))
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to export **%v** site deploys.\n"+
+				"*Error : %v*", siteName, err.Error()))
+		return
+	}
+
+	fileName := fmt.Sprintf("%v-deploys-%v.csv", siteName, time.Now().Format("2006-01-02-150405"))
+
+	fileInfo, appErr := p.API.UploadFile(deploysInCSV.Bytes(), channelID, fileName)
+	if appErr != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to upload export of **%v** site deploys.\n"+
+				"*Error : %v*", siteName, appErr.Error()))
+		return
+	}
+
+	_, appErr = p.API.CreatePost(&model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Message: fmt.Sprintf(":page_facing_up: %v exported %v deploys of **%v** site%v.",
+			p.getUserMention(userID), len(deploys), siteName, describeDeployHistoryFilters(branch, state, since, 0)),
+		FileIds: []string{fileInfo.Id},
+	})
+	if appErr != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to post export of **%v** site deploys.\n"+
+				"*Error : %v*", siteName, appErr.Error()))
+	}
+}
+
+// listFilteredSiteDeploys returns a single page of deploys of a site which match the filters.
+// It also tells if older deploys matching the filters could be there on next pages.
+func (p *Plugin) listFilteredSiteDeploys(userID, siteID string, page, perPage int, branch, state, since string) ([]*NetlifyDeploy, bool, error) {
+	// Branch and state are filtered at Netlify itself
+	deploysQuery := url.Values{}
+	if len(branch) != 0 {
+		deploysQuery.Set("branch", branch)
+	}
+	if len(state) != 0 {
+		deploysQuery.Set("state", state)
+	}
+
+	var sinceDate time.Time
+	if len(since) != 0 {
+		sinceDate, _ = parseDateFlag(since)
+	}
+
+	deploysOfPage, err := p.listSiteDeploys(userID, siteID, page, perPage, deploysQuery)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// A page smaller than asked for means there are no older deploys
+	hasOlderDeploys := len(deploysOfPage) == perPage
+
+	var deploys []*NetlifyDeploy
+	for _, deploy := range deploysOfPage {
+		if len(branch) != 0 && deploy.Branch != branch {
+			continue
+		}
+
+		if len(state) != 0 && deploy.State != state {
+			continue
+		}
+
+		// Deploys are sorted newest first, so rest of them are older too
+		deployCreatedAt, err := time.Parse(NetlifyDateLayout, deploy.CreatedAt)
+		if err == nil && !sinceDate.IsZero() && deployCreatedAt.Before(sinceDate) {
+			hasOlderDeploys = false
+			break
+		}
+
+		deploys = append(deploys, deploy)
+	}
+
+	return deploys, hasOlderDeploys, nil
+}
+
+// describeDeployHistoryFilters returns the filters applied on deploy history in readable form
+func describeDeployHistoryFilters(branch, state, since string, limit int) string {
+	var filters []string
+	if len(branch) != 0 {
+		filters = append(filters, "branch "+branch)
+	}
+	if len(state) != 0 {
+		filters = append(filters, "state "+state)
+	}
+	if len(since) != 0 {
+		filters = append(filters, "since "+since)
+	}
+	if limit != 0 {
+		filters = append(filters, fmt.Sprintf("limited to %v deploys", limit))
+	}
+
+	if len(filters) == 0 {
+		return ""
+	}
+
+	return " filtered by " + strings.Join(filters, ", ")
+}
+
+// describeDeployTitle returns first line of the commit message of the deploy
+func describeDeployTitle(title string) string {
+	if len(title) == 0 {
+		return "*Deployed via webhook or manually*"
+	}
+
+	return strings.Split(title, "\n")[0]
+}
+
+// describeDeploySHA returns the short SHA of the commit deployed
+func describeDeploySHA(commitRef string) string {
+	if len(commitRef) == 0 {
+		return "-"
+	}
+
+	if len(commitRef) > 7 {
+		return commitRef[:7]
+	}
+
+	return commitRef
+}
+
+func describeDeployAuthor(committer string) string {
+	if len(committer) == 0 {
+		return "-"
+	}
+
+	return committer
+}
+
+// describeDeployTime returns the time taken by a deploy to build in readable form
+func describeDeployTime(deployTime int64) string {
+	if deployTime == 0 {
+		return "-"
+	}
+
+	return (time.Duration(deployTime) * time.Second).String()
+}
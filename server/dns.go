@@ -0,0 +1,338 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+)
+
+// dnsRecordTypes are the types of records Netlify DNS supports
+var dnsRecordTypes = []string{"A", "AAAA", "ALIAS", "CAA", "CNAME", "MX", "NS", "SPF", "SRV", "TXT"}
+
+func (p *Plugin) handleDNSCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	arguments, flags := parseCommandFlags(parameters)
+
+	subcommand := ""
+	if len(arguments) != 0 {
+		subcommand = arguments[0]
+	}
+
+	switch {
+	// "/netlify dns zones"
+	case subcommand == "zones" && len(arguments) == 1:
+		p.sendDNSZonesPost(userID, channelID)
+	// "/netlify dns records <zone>"
+	case subcommand == "records" && len(arguments) == 2:
+		p.sendDNSRecordsPost(userID, channelID, arguments[1])
+	// "/netlify dns add <zone> <type> <hostname> <value> [--ttl 3600] [--priority 10]"
+	case subcommand == "add" && len(arguments) >= 5:
+		p.addDNSRecord(userID, channelID, arguments[1], arguments[2], arguments[3], strings.Join(arguments[4:], " "), flags["ttl"], flags["priority"])
+	// "/netlify dns delete <zone> <recordID>"
+	case subcommand == "delete" && len(arguments) == 3:
+		p.sendDNSRecordDeleteConfirmation(userID, channelID, arguments[1], arguments[2])
+	default:
+		p.sendMessageFromBot(channelID, userID, true, "Please use one of the below dns commands\n"+
+			"* `/netlify dns zones`\n"+
+			"* `/netlify dns records <zone>`\n"+
+			"* `/netlify dns add <zone> <type> <hostname> <value> [--ttl 3600] [--priority 10]`\n"+
+			"* `/netlify dns delete <zone> <recordID>`")
+	}
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) sendDNSZonesPost(userID string, channelID string) {
+	dnsZones, err := p.listDNSZones(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get DNS zones.\n"+
+				"*Error : %v*", err.Error()))
+		return
+	}
+
+	if len(dnsZones) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, ":spider_web: You don't seem to have any DNS zones with Netlify")
+		return
+	}
+
+	// Create a table with just the header, rows will fill up in the loop
+	var dnsZonesMarkdownTable string = MarkdownDNSZoneTableHeader
+	for _, dnsZone := range dnsZones {
+		dnsZonesMarkdownTable = fmt.Sprintf("%v\n| %v | %v |", dnsZonesMarkdownTable, dnsZone.Name, dnsZone.ID)
+	}
+
+	p.sendMessageFromBot(channelID, userID, true, "#### DNS zones\n"+dnsZonesMarkdownTable)
+}
+
+func (p *Plugin) sendDNSRecordsPost(userID string, channelID string, zoneNameOrID string) {
+	dnsZone, err := p.getDNSZoneFromCommandArgument(userID, zoneNameOrID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the DNS zone\n"+
+				"*Error : %v*", err.Error()))
+		return
+	}
+
+	var dnsRecords []*netlifyModels.DNSRecord
+	err = p.sendNetlifyAPIRequest(userID, http.MethodGet, fmt.Sprintf("/dns_zones/%v/dns_records", dnsZone.ID), nil, nil, &dnsRecords)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get records of **%v** DNS zone.\n"+
+				"*Error : %v*", dnsZone.Name, err.Error()))
+		return
+	}
+
+	if len(dnsRecords) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: **%v** DNS zone has no records", dnsZone.Name))
+		return
+	}
+
+	// Create a table with just the header, rows will fill up in the loop
+	var dnsRecordsMarkdownTable string = MarkdownDNSRecordTableHeader
+	for _, dnsRecord := range dnsRecords {
+		var priority string = "-"
+		if dnsRecord.Priority != 0 {
+			priority = strconv.FormatInt(dnsRecord.Priority, 10)
+		}
+
+		dnsRecordsMarkdownTable = fmt.Sprintf("%v\n| %v | %v | %v | %v | %v | %v |", dnsRecordsMarkdownTable,
+			dnsRecord.Type, dnsRecord.Hostname, strings.Replace(dnsRecord.Value, "|", "\\|", -1), dnsRecord.TTL, priority, dnsRecord.ID)
+	}
+
+	p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("#### DNS records of %v\n%v", dnsZone.Name, dnsRecordsMarkdownTable))
+}
+
+func (p *Plugin) addDNSRecord(userID, channelID, zoneNameOrID, recordType, hostname, value, ttl, priority string) {
+	recordType = strings.ToUpper(recordType)
+
+	var isValidRecordType bool = false
+	for _, dnsRecordType := range dnsRecordTypes {
+		if dnsRecordType == recordType {
+			isValidRecordType = true
+			break
+		}
+	}
+
+	if isValidRecordType == false {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: `%v` is not a supported record type, it can be one of %v", recordType, strings.Join(dnsRecordTypes, ", ")))
+		return
+	}
+
+	dnsRecord := &netlifyModels.DNSRecord{
+		Type:  recordType,
+		Value: value,
+	}
+
+	if len(ttl) != 0 {
+		ttlParsed, err := strconv.ParseInt(ttl, 10, 64)
+		if err != nil || ttlParsed <= 0 {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":exclamation: Invalid value of --ttl flag, %v is not a positive number", ttl))
+			return
+		}
+		dnsRecord.TTL = ttlParsed
+	}
+
+	if len(priority) != 0 {
+		priorityParsed, err := strconv.ParseInt(priority, 10, 64)
+		if err != nil || priorityParsed < 0 {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":exclamation: Invalid value of --priority flag, %v is not a number", priority))
+			return
+		}
+		dnsRecord.Priority = priorityParsed
+	}
+
+	dnsZone, err := p.getDNSZoneFromCommandArgument(userID, zoneNameOrID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the DNS zone\n"+
+				"*Error : %v*", err.Error()))
+		return
+	}
+
+	// Hostname can be given relative to the zone eg. www for www.example.com or @ for example.com
+	hostname = strings.TrimSuffix(strings.ToLower(hostname), ".")
+	if hostname == "@" {
+		hostname = dnsZone.Name
+	} else if hostname != dnsZone.Name && !strings.HasSuffix(hostname, "."+dnsZone.Name) {
+		hostname = hostname + "." + dnsZone.Name
+	}
+	dnsRecord.Hostname = hostname
+
+	createdDNSRecord := &netlifyModels.DNSRecord{}
+	err = p.sendNetlifyAPIRequest(userID, http.MethodPost, fmt.Sprintf("/dns_zones/%v/dns_records", dnsZone.ID), nil, dnsRecord, createdDNSRecord)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to add %v record for %v to **%v** DNS zone.\n"+
+				"*Error : %v*", recordType, hostname, dnsZone.Name, err.Error()))
+		return
+	}
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(":card_index: %v added %v record %v pointing to `%v` to **%v** DNS zone. Record ID %v",
+		p.getUserMention(userID), createdDNSRecord.Type, createdDNSRecord.Hostname, createdDNSRecord.Value, dnsZone.Name, createdDNSRecord.ID))
+}
+
+func (p *Plugin) sendDNSRecordDeleteConfirmation(userID string, channelID string, zoneNameOrID string, recordID string) {
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		p.sendMessageFromBot(channelID, userID, true, "Error! Site URL is not defined in the App")
+		return
+	}
+
+	dnsZone, err := p.getDNSZoneFromCommandArgument(userID, zoneNameOrID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the DNS zone\n"+
+				"*Error : %v*", err.Error()))
+		return
+	}
+
+	// Show what is going to be deleted, which also makes sure the record exists
+	dnsRecord := &netlifyModels.DNSRecord{}
+	err = p.sendNetlifyAPIRequest(userID, http.MethodGet, fmt.Sprintf("/dns_zones/%v/dns_records/%v", dnsZone.ID, recordID), nil, nil, dnsRecord)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find %v record in **%v** DNS zone.\n"+
+				"*Error : %v*", recordID, dnsZone.Name, err.Error()))
+		return
+	}
+
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	buttonContext := func(action string) map[string]interface{} {
+		return map[string]interface{}{
+			"action":       action,
+			"actionSecret": actionSecret,
+			"zoneID":       dnsZone.ID,
+			"zoneName":     dnsZone.Name,
+			"recordID":     dnsRecord.ID,
+		}
+	}
+
+	deleteButton := &model.PostAction{
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Name: "Delete",
+		Integration: &model.PostActionIntegration{
+			URL:     fmt.Sprintf("%s/plugins/netlify/command/dns-delete", *siteURL),
+			Context: buttonContext(ActionDeleteDNSRecord),
+		},
+	}
+
+	cancelButton := &model.PostAction{
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Name: "Cancel",
+		Integration: &model.PostActionIntegration{
+			URL:     fmt.Sprintf("%s/plugins/netlify/command/dns-delete", *siteURL),
+			Context: buttonContext(ActionCancel),
+		},
+	}
+
+	deleteMessageAttachment := &model.SlackAttachment{
+		Title: fmt.Sprintf("Delete DNS record of %v", dnsZone.Name),
+		Text: fmt.Sprintf(":warning: Are you sure you would like to delete %v record %v pointing to `%v`?\n"+
+			"Traffic relying on this record may stop reaching its destination.", dnsRecord.Type, dnsRecord.Hostname, dnsRecord.Value),
+		Actions: []*model.PostAction{deleteButton, cancelButton},
+	}
+
+	p.API.SendEphemeralPost(userID, &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": []*model.SlackAttachment{deleteMessageAttachment},
+		},
+	})
+}
+
+func (p *Plugin) handleDNSRecordDeleteResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+	originalPostID := intergrationResponseFromCommand.PostId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	actionToBeTaken, _ := intergrationResponseFromCommand.Context["action"].(string)
+	zoneID, _ := intergrationResponseFromCommand.Context["zoneID"].(string)
+	zoneName, _ := intergrationResponseFromCommand.Context["zoneName"].(string)
+	recordID, _ := intergrationResponseFromCommand.Context["recordID"].(string)
+
+	if actionToBeTaken != ActionDeleteDNSRecord {
+		p.API.UpdateEphemeralPost(userID, &model.Post{
+			Id:        originalPostID,
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message:   fmt.Sprintf(":ok_hand: %v record of **%v** DNS zone was not deleted.", recordID, zoneName),
+		})
+		return
+	}
+
+	err := p.sendNetlifyAPIRequest(userID, http.MethodDelete, fmt.Sprintf("/dns_zones/%v/dns_records/%v", zoneID, recordID), nil, nil, nil)
+	if err != nil {
+		p.API.UpdateEphemeralPost(userID, &model.Post{
+			Id:        originalPostID,
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(":exclamation: Failed to delete %v record of **%v** DNS zone.\n"+
+				"*Error : %v*", recordID, zoneName, err.Error()),
+		})
+		return
+	}
+
+	p.API.DeleteEphemeralPost(userID, originalPostID)
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(":wastebasket: %v deleted %v record of **%v** DNS zone.",
+		p.getUserMention(userID), recordID, zoneName))
+}
+
+// listDNSZones returns all the DNS zones of the user managed by Netlify DNS
+func (p *Plugin) listDNSZones(userID string) ([]*netlifyModels.DNSZone, error) {
+	var dnsZones []*netlifyModels.DNSZone
+	err := p.sendNetlifyAPIRequest(userID, http.MethodGet, "/dns_zones", nil, nil, &dnsZones)
+	if err != nil {
+		return nil, err
+	}
+
+	return dnsZones, nil
+}
+
+// getDNSZoneFromCommandArgument returns the DNS zone of the user whose name or id is same as passed in the command.
+func (p *Plugin) getDNSZoneFromCommandArgument(userID string, zoneNameOrID string) (*netlifyModels.DNSZone, error) {
+	dnsZones, err := p.listDNSZones(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Zone names are case insensitive and can be written with a trailing dot
+	zoneName := strings.TrimSuffix(strings.ToLower(zoneNameOrID), ".")
+	for _, dnsZone := range dnsZones {
+		if dnsZone.ID == zoneNameOrID || dnsZone.Name == zoneName {
+			return dnsZone, nil
+		}
+	}
+
+	return nil, fmt.Errorf("No DNS zone found by the name or id %v", zoneNameOrID)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+)
+
+// hostnamePattern matches fully qualified hostnames like www.example.com
+var hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+func (p *Plugin) handleDomainCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	// Eg. /netlify domain add-alias <site> www.example.com
+	if len(parameters) != 3 {
+		p.sendMessageFromBot(channelID, userID, true,
+			"Please mention the subcommand, the site and the domain eg. `/netlify domain set|add-alias|remove-alias <site> <domain>`")
+		return &model.CommandResponse{}, nil
+	}
+
+	subcommand := parameters[0]
+	if subcommand != "set" && subcommand != "add-alias" && subcommand != "remove-alias" {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			"Unknown subcommand `%v`, it can either be set, add-alias or remove-alias eg. `/netlify domain set <site> <domain>`", subcommand))
+		return &model.CommandResponse{}, nil
+	}
+
+	domain := strings.TrimSuffix(strings.ToLower(parameters[2]), ".")
+	if len(domain) > 253 || !hostnamePattern.MatchString(domain) {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":exclamation: `%v` is not a valid hostname eg. www.example.com", parameters[2]))
+		return &model.CommandResponse{}, nil
+	}
+
+	siteFound, err := p.resolveSiteForChange(userID, parameters[1])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Aliases are changed as a whole, so the latest ones are needed
+	site, err := p.getSiteByID(userID, siteFound.ID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get **%v** site.\n"+
+				"*Error : %v*", siteFound.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	siteUpdate := &netlifyModels.Site{}
+	var changeMessage string
+
+	switch subcommand {
+	case "set":
+		if site.CustomDomain == domain {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: %v is already the custom domain of **%v** site", domain, site.Name))
+			return &model.CommandResponse{}, nil
+		}
+
+		siteUpdate.CustomDomain = domain
+		changeMessage = fmt.Sprintf(":globe_with_meridians: %v set the custom domain of **%v** site to %v.", p.getUserMention(userID), site.Name, domain)
+		if len(site.CustomDomain) != 0 {
+			changeMessage = fmt.Sprintf(":globe_with_meridians: %v changed the custom domain of **%v** site from %v to %v.",
+				p.getUserMention(userID), site.Name, site.CustomDomain, domain)
+		}
+	case "add-alias":
+		domainAliases := []string{}
+		for _, domainAlias := range site.DomainAliases {
+			if domainAlias == domain {
+				p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: %v is already a domain alias of **%v** site", domain, site.Name))
+				return &model.CommandResponse{}, nil
+			}
+			domainAliases = append(domainAliases, domainAlias)
+		}
+
+		siteUpdate.DomainAliases = append(domainAliases, domain)
+		changeMessage = fmt.Sprintf(":globe_with_meridians: %v added %v as a domain alias of **%v** site.", p.getUserMention(userID), domain, site.Name)
+	case "remove-alias":
+		// Non nil, so that removing the last alias is sent to Netlify as well
+		domainAliases := []string{}
+		for _, domainAlias := range site.DomainAliases {
+			if domainAlias != domain {
+				domainAliases = append(domainAliases, domainAlias)
+			}
+		}
+
+		if len(domainAliases) == len(site.DomainAliases) {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: %v is not a domain alias of **%v** site", domain, site.Name))
+			return &model.CommandResponse{}, nil
+		}
+
+		siteUpdate.DomainAliases = domainAliases
+		changeMessage = fmt.Sprintf(":globe_with_meridians: %v removed %v from domain aliases of **%v** site.", p.getUserMention(userID), domain, site.Name)
+	}
+
+	_, err = p.updateSite(userID, site, siteUpdate)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to update domains of **%v** site.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	err = p.recordAuditEvent(site.ID, userID, "domain_"+strings.Replace(subcommand, "-", "_", -1), domain)
+	if err != nil {
+		p.API.LogError("Failed to save audit record of domain change", "site_id", site.ID, "error", err.Error())
+	}
+
+	// Removed alias has nothing to be covered by the certificate
+	domainToBeCovered := domain
+	if subcommand == "remove-alias" {
+		domainToBeCovered = ""
+	}
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf("%v\n%v", changeMessage, p.describeSiteSSLState(userID, site.ID, domainToBeCovered)))
+
+	return &model.CommandResponse{}, nil
+}
+
+// describeSiteSSLState returns a sentence telling the state of the certificate of the site along with the domains it covers.
+// If domainToBeCovered is not empty, it also tells whether the certificate covers that domain yet.
+func (p *Plugin) describeSiteSSLState(userID string, siteID string, domainToBeCovered string) string {
+	certificate, err := p.getSiteTLSCertificate(userID, siteID)
+	if err != nil || certificate == nil || len(certificate.State) == 0 {
+		return ":unlock: *SSL* : No certificate is provisioned yet. Netlify provisions one once DNS of the domains points to Netlify."
+	}
+
+	sslState := fmt.Sprintf(":closed_lock_with_key: *SSL* : Certificate is %v for %v, expires on %v.",
+		certificate.State, strings.Join(certificate.Domains, ", "), formatNetlifyDate(certificate.ExpiresAt))
+
+	if len(domainToBeCovered) == 0 {
+		return sslState
+	}
+
+	for _, certificateDomain := range certificate.Domains {
+		if certificateDomain == domainToBeCovered {
+			return sslState
+		}
+	}
+
+	return fmt.Sprintf("%v It doesn't cover %v yet, Netlify renews it to include the domain once its DNS points to Netlify.", sslState, domainToBeCovered)
+}
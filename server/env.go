@@ -0,0 +1,374 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
+)
+
+// environmentVariableKeyPattern is what Netlify accepts as name of an environment variable
+var environmentVariableKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (p *Plugin) handleEnvCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	// Eg. /netlify env set <site> NODE_VERSION 12 --redeploy
+	arguments, flags := parseCommandFlags(parameters, "redeploy")
+	if len(arguments) < 2 {
+		p.sendMessageFromBot(channelID, userID, true,
+			"Please mention the subcommand and the site eg. `/netlify env list|get|set|unset <site> [key] [value] [--redeploy]`")
+		return &model.CommandResponse{}, nil
+	}
+
+	subcommand := arguments[0]
+	siteSearchTerm := arguments[1]
+	isRedeploy := flags["redeploy"] == "true"
+
+	var key, value string
+	switch subcommand {
+	case "list":
+		if len(arguments) != 2 {
+			p.sendMessageFromBot(channelID, userID, true, "Please mention only the site eg. `/netlify env list <site>`")
+			return &model.CommandResponse{}, nil
+		}
+	case "get", "unset":
+		if len(arguments) != 3 {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Please mention the site and the key eg. `/netlify env %v <site> <key>`", subcommand))
+			return &model.CommandResponse{}, nil
+		}
+		key = arguments[2]
+	case "set":
+		// Value is read from the command as typed, as it can have flags of its own like NODE_OPTIONS does
+		siteSearchTerm, key, value, isRedeploy = parseEnvSetCommand(args.Command)
+		if len(value) == 0 {
+			p.sendMessageFromBot(channelID, userID, true, "Please mention the site, the key and its value eg. `/netlify env set <site> <key> <value> [--redeploy]`")
+			return &model.CommandResponse{}, nil
+		}
+	default:
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			"Unknown subcommand `%v`, it can either be list, get, set or unset eg. `/netlify env list <site>`", subcommand))
+		return &model.CommandResponse{}, nil
+	}
+
+	if len(key) != 0 && !environmentVariableKeyPattern.MatchString(key) {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: `%v` is not a valid key, it can only have letters, numbers and underscores and can't start with a number", key))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Only changes need the site to be named exactly, looking at variables is fine with a loose match
+	resolveSite := p.resolveSite
+	if subcommand == "set" || subcommand == "unset" {
+		resolveSite = p.resolveSiteForChange
+	}
+
+	siteFound, err := resolveSite(userID, siteSearchTerm)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Sites listing doesn't always carry build settings, hence site is fetched again
+	site, err := p.getSiteByID(userID, siteFound.ID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get **%v** site.\n"+
+				"*Error : %v*", siteFound.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	environmentVariables := map[string]string{}
+	if site.BuildSettings != nil && site.BuildSettings.Env != nil {
+		environmentVariables = site.BuildSettings.Env
+	}
+
+	if subcommand == "list" || subcommand == "get" {
+		if subcommand == "get" {
+			if _, ok := environmentVariables[key]; !ok {
+				p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: **%v** site has no `%v` environment variable", site.Name, key))
+				return &model.CommandResponse{}, nil
+			}
+		}
+
+		p.sendEnvironmentVariablesPost(userID, channelID, site, environmentVariables, key, false)
+		return &model.CommandResponse{}, nil
+	}
+
+	// Rest of the build settings are kept as it is, only environment variables change
+	buildSettings := &netlifyModels.RepoInfo{}
+	if site.BuildSettings != nil {
+		*buildSettings = *site.BuildSettings
+	}
+
+	updatedEnvironmentVariables := map[string]string{}
+	for existingKey, existingValue := range environmentVariables {
+		updatedEnvironmentVariables[existingKey] = existingValue
+	}
+
+	var changeMessage, auditAction string
+	if subcommand == "set" {
+		_, isExistingKey := environmentVariables[key]
+		updatedEnvironmentVariables[key] = value
+
+		auditAction = "env_set"
+		changeMessage = fmt.Sprintf(":wrench: %v added `%v` environment variable to **%v** site.", p.getUserMention(userID), key, site.Name)
+		if isExistingKey == true {
+			changeMessage = fmt.Sprintf(":wrench: %v changed `%v` environment variable of **%v** site.", p.getUserMention(userID), key, site.Name)
+		}
+	} else {
+		if _, ok := environmentVariables[key]; !ok {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: **%v** site has no `%v` environment variable", site.Name, key))
+			return &model.CommandResponse{}, nil
+		}
+		delete(updatedEnvironmentVariables, key)
+
+		auditAction = "env_unset"
+		changeMessage = fmt.Sprintf(":wastebasket: %v removed `%v` environment variable from **%v** site.", p.getUserMention(userID), key, site.Name)
+	}
+
+	updatedSite, err := p.updateSiteEnvironmentVariables(userID, site.ID, buildSettings, updatedEnvironmentVariables)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to update environment variables of **%v** site.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Make sure Netlify took the change before telling it was done
+	var updatedValue string
+	var isKeyUpdated bool
+	if updatedSite.BuildSettings != nil {
+		updatedValue, isKeyUpdated = updatedSite.BuildSettings.Env[key]
+	}
+
+	if (subcommand == "set" && (isKeyUpdated == false || updatedValue != value)) || (subcommand == "unset" && isKeyUpdated == true) {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Netlify did not apply the change to `%v` environment variable of **%v** site, please check it with `/netlify env list %v`",
+			key, site.Name, site.Name))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Value is never written to the audit record
+	err = p.recordAuditEvent(site.ID, userID, auditAction, fmt.Sprintf("key %v", key))
+	if err != nil {
+		p.API.LogError("Failed to save audit record of environment variable change", "site_id", site.ID, "error", err.Error())
+	}
+
+	changeMessage = changeMessage + " Changes take effect on the next deploy."
+	if isRedeploy == true {
+		build, err := p.redeploySite(userID, site.ID)
+		if err != nil {
+			changeMessage = fmt.Sprintf("%v\n:exclamation: Redeploy could not be started. *Error : %v*", changeMessage, err.Error())
+		} else {
+			changeMessage = fmt.Sprintf("%v\n:satellite: Redeploy of the site has started, deploy ID %v.", changeMessage, build.DeployID)
+		}
+	}
+
+	p.sendMessageFromBot(channelID, "", false, changeMessage)
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleEnvRevealResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	siteID, _ := intergrationResponseFromCommand.Context["siteID"].(string)
+	key, _ := intergrationResponseFromCommand.Context["key"].(string)
+
+	// Values are fetched again with credentials of whoever clicked, rather than being carried in the post
+	site, err := p.getSiteByID(userID, siteID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get environment variables of the site.\n"+
+				"*Error : %v*", err.Error()))
+		return
+	}
+
+	environmentVariables := map[string]string{}
+	if site.BuildSettings != nil && site.BuildSettings.Env != nil {
+		environmentVariables = site.BuildSettings.Env
+	}
+
+	p.sendEnvironmentVariablesPost(userID, channelID, site, environmentVariables, key, true)
+}
+
+// sendEnvironmentVariablesPost sends an ephemeral post to the user with the environment variables of the site in a table.
+// If key is not empty only that variable is shown. Masked values come along with a button to reveal them.
+func (p *Plugin) sendEnvironmentVariablesPost(userID string, channelID string, site *netlifyModels.Site, environmentVariables map[string]string, key string, revealValues bool) {
+	if len(environmentVariables) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: **%v** site has no environment variables", site.Name))
+		return
+	}
+
+	var keys []string
+	for existingKey := range environmentVariables {
+		if len(key) == 0 || existingKey == key {
+			keys = append(keys, existingKey)
+		}
+	}
+	sort.Strings(keys)
+
+	// Create a table with just the header, rows will fill up in the loop
+	var environmentVariablesMarkdownTable string = MarkdownEnvironmentVariableTableHeader
+	for _, existingKey := range keys {
+		value := EnvironmentVariableMaskedValue
+		if revealValues == true {
+			value = fmt.Sprintf("`%v`", strings.Replace(environmentVariables[existingKey], "|", "\\|", -1))
+		}
+
+		environmentVariablesMarkdownTable = fmt.Sprintf("%v\n| %v | %v |", environmentVariablesMarkdownTable, existingKey, value)
+	}
+
+	environmentVariablesAttachment := &model.SlackAttachment{
+		Title: fmt.Sprintf("Environment variables of %v site", site.Name),
+		Text:  environmentVariablesMarkdownTable,
+	}
+
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if revealValues == false && siteURL != nil {
+		environmentVariablesAttachment.Footer = "Values are masked, revealing them shows them only to you"
+		environmentVariablesAttachment.Actions = []*model.PostAction{{
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Name: "Reveal values",
+			Integration: &model.PostActionIntegration{
+				URL: fmt.Sprintf("%s/plugins/netlify/command/env-reveal", *siteURL),
+				Context: map[string]interface{}{
+					"actionSecret": p.getConfiguration().EncryptionKey,
+					"siteID":       site.ID,
+					"key":          key,
+				},
+			},
+		}}
+	}
+
+	p.API.SendEphemeralPost(userID, &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": []*model.SlackAttachment{environmentVariablesAttachment},
+		},
+	})
+}
+
+// redeploySite starts a new build of the site from its latest commit
+func (p *Plugin) redeploySite(userID string, siteID string) (*netlifyModels.Build, error) {
+	// Get the Netlify library client for interacting with netlify api
+	netlifyClient, ctx := p.getNetlifyClient()
+
+	// Get Netlify credentials
+	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	createSiteBuildParams := &netlifyPlumbingModels.CreateSiteBuildParams{
+		SiteID:  siteID,
+		Context: ctx,
+	}
+
+	createSiteBuildResponse, err := netlifyClient.Operations.CreateSiteBuild(createSiteBuildParams, netlifyCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return createSiteBuildResponse.GetPayload(), nil
+}
+
+// updateSiteEnvironmentVariables replaces the environment variables of the site, keeping rest of its build settings.
+// Netlify library leaves out empty environment variables, which would keep the last variable from being removed,
+// so the update is sent as is to the same endpoint UpdateSite uses.
+func (p *Plugin) updateSiteEnvironmentVariables(userID string, siteID string, buildSettings *netlifyModels.RepoInfo, environmentVariables map[string]string) (*netlifyModels.Site, error) {
+	buildSettingsInBytes, err := json.Marshal(buildSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	buildSettingsUpdate := map[string]interface{}{}
+	err = json.Unmarshal(buildSettingsInBytes, &buildSettingsUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	buildSettingsUpdate["env"] = environmentVariables
+
+	updatedSite := &netlifyModels.Site{}
+	err = p.sendNetlifyAPIRequest(userID, http.MethodPatch, fmt.Sprintf("/sites/%v", siteID), nil,
+		map[string]interface{}{"build_settings": buildSettingsUpdate}, updatedSite)
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedSite, nil
+}
+
+// parseEnvSetCommand reads the site, key and value from "/netlify env set <site> <key> <value> [--redeploy]" as typed.
+// Everything after the key is the value, keeping its spaces and any "--" in it, except --redeploy at the very end.
+func parseEnvSetCommand(command string) (string, string, string, bool) {
+	var words []string
+	rest := command
+	for len(words) < 5 {
+		rest = strings.TrimLeft(rest, " \t")
+		if len(rest) == 0 {
+			break
+		}
+
+		wordEnd := strings.IndexAny(rest, " \t")
+		if wordEnd == -1 {
+			wordEnd = len(rest)
+		}
+
+		words = append(words, rest[:wordEnd])
+		rest = rest[wordEnd:]
+	}
+
+	// Words are /netlify env set <site> <key>
+	if len(words) < 5 {
+		return "", "", "", false
+	}
+
+	value := strings.TrimSpace(rest)
+
+	isRedeploy := false
+	if value == "--redeploy" || strings.HasSuffix(value, " --redeploy") || strings.HasSuffix(value, "\t--redeploy") {
+		isRedeploy = true
+		value = strings.TrimSpace(strings.TrimSuffix(value, "--redeploy"))
+	}
+
+	// Quotes around the whole value are only there to keep it together
+	if len(value) > 1 && strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+		value = value[1 : len(value)-1]
+	}
+
+	return words[3], words[4], value, isRedeploy
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnvSetCommand(t *testing.T) {
+	for name, test := range map[string]struct {
+		Command            string
+		ExpectedSite       string
+		ExpectedKey        string
+		ExpectedValue      string
+		ExpectedIsRedeploy bool
+	}{
+		"single word value": {
+			Command:       "/netlify env set blog NODE_VERSION 12",
+			ExpectedSite:  "blog",
+			ExpectedKey:   "NODE_VERSION",
+			ExpectedValue: "12",
+		},
+		"value starting with dashes": {
+			Command:       "/netlify env set blog NODE_OPTIONS --max-old-space-size=4096",
+			ExpectedSite:  "blog",
+			ExpectedKey:   "NODE_OPTIONS",
+			ExpectedValue: "--max-old-space-size=4096",
+		},
+		"value with flags and redeploy at the end": {
+			Command:            "/netlify env set blog NODE_OPTIONS --max-old-space-size=4096 --trace-warnings --redeploy",
+			ExpectedSite:       "blog",
+			ExpectedKey:        "NODE_OPTIONS",
+			ExpectedValue:      "--max-old-space-size=4096 --trace-warnings",
+			ExpectedIsRedeploy: true,
+		},
+		"redeploy before the value is part of it": {
+			Command:       "/netlify env set blog BUILD_FLAGS --redeploy 12",
+			ExpectedSite:  "blog",
+			ExpectedKey:   "BUILD_FLAGS",
+			ExpectedValue: "--redeploy 12",
+		},
+		"quoted value keeps repeated spaces": {
+			Command:       `/netlify env set blog GREETING "hello   world"`,
+			ExpectedSite:  "blog",
+			ExpectedKey:   "GREETING",
+			ExpectedValue: "hello   world",
+		},
+		"extra spaces between words": {
+			Command:            "/netlify  env set   blog   NODE_VERSION   12   --redeploy",
+			ExpectedSite:       "blog",
+			ExpectedKey:        "NODE_VERSION",
+			ExpectedValue:      "12",
+			ExpectedIsRedeploy: true,
+		},
+		"only redeploy": {
+			Command:            "/netlify env set blog NODE_VERSION --redeploy",
+			ExpectedSite:       "blog",
+			ExpectedKey:        "NODE_VERSION",
+			ExpectedIsRedeploy: true,
+		},
+		"no key": {
+			Command: "/netlify env set blog",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			site, key, value, isRedeploy := parseEnvSetCommand(test.Command)
+			assert.Equal(t, test.ExpectedSite, site)
+			assert.Equal(t, test.ExpectedKey, key)
+			assert.Equal(t, test.ExpectedValue, value)
+			assert.Equal(t, test.ExpectedIsRedeploy, isRedeploy)
+		})
+	}
+}
@@ -0,0 +1,596 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
+)
+
+func (p *Plugin) handleFormsCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	arguments, flags := parseCommandFlags(parameters, "public")
+	if len(arguments) != 1 {
+		p.sendMessageFromBot(channelID, userID, true, "Please mention the site eg. `/netlify forms <site> [--public]`")
+		return &model.CommandResponse{}, nil
+	}
+
+	site, err := p.resolveSite(userID, arguments[0])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Get the Netlify library client for interacting with netlify api
+	netlifyClient, ctx := p.getNetlifyClient()
+
+	// Get Netlify credentials
+	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	listSiteFormsParams := &netlifyPlumbingModels.ListSiteFormsParams{
+		SiteID:  site.ID,
+		Context: ctx,
+	}
+
+	listSiteFormsResponse, err := netlifyClient.Operations.ListSiteForms(listSiteFormsParams, netlifyCredentials)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get forms of **%v** site.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	forms := listSiteFormsResponse.GetPayload()
+	if len(forms) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: **%v** site has no forms", site.Name))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Create a table with just the header, rows will fill up in the loop
+	var formsMarkdownTable string = MarkdownFormTableHeader
+	for _, form := range forms {
+		formsMarkdownTable = fmt.Sprintf("%v\n| %v | %v | %v | %v | %v |", formsMarkdownTable,
+			form.Name, form.SubmissionCount, strings.Join(form.Paths, ", "), formatNetlifyDate(form.CreatedAt), form.ID)
+	}
+
+	// Forms don't carry personal information, but are kept private unless asked otherwise like submissions
+	isEphemeralPost := flags["public"] != "true"
+	p.sendMessageFromBot(channelID, userID, isEphemeralPost, fmt.Sprintf("#### Forms of %v\n%v", site.Name, formsMarkdownTable))
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleSubmissionsCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	arguments, flags := parseCommandFlags(parameters, "public")
+
+	// "/netlify submissions export <form> [--since date]"
+	if len(arguments) != 0 && arguments[0] == "export" {
+		return p.handleSubmissionsExportCommand(args, arguments[1:], flags)
+	}
+
+	if len(arguments) != 1 {
+		p.sendMessageFromBot(channelID, userID, true, "Please mention the form eg. `/netlify submissions <form> [--limit N] [--public]`")
+		return &model.CommandResponse{}, nil
+	}
+
+	limit := SubmissionsDefaultLimit
+	if limitFlag, ok := flags["limit"]; ok {
+		limitParsed, err := strconv.Atoi(limitFlag)
+		if err != nil || limitParsed <= 0 || limitParsed > SubmissionsPerPage {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Invalid value of --limit flag, it should be a number between 1 and %v", SubmissionsPerPage))
+			return &model.CommandResponse{}, nil
+		}
+		limit = limitParsed
+	}
+
+	form, err := p.getFormFromCommandArgument(userID, arguments[0])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the form\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	submissions, err := p.listFormSubmissions(userID, form.ID, 1, limit)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get submissions of **%v** form.\n"+
+				"*Error : %v*", form.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	if len(submissions) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: **%v** form has no submissions", form.Name))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Create a table with just the header, rows will fill up in the loop
+	var submissionsMarkdownTable string = MarkdownSubmissionTableHeader
+	for _, submission := range submissions {
+		submissionsMarkdownTable = fmt.Sprintf("%v\n| %v | %v | %v | %v | %v | %v |", submissionsMarkdownTable,
+			submission.Number, formatNetlifyDate(submission.CreatedAt), escapeMarkdownTableCell(submission.Name),
+			escapeMarkdownTableCell(submission.Email), escapeMarkdownTableCell(submission.Summary), submission.ID)
+	}
+
+	submissionsText := fmt.Sprintf("#### Recent submissions of %v\n%v", form.Name, submissionsMarkdownTable)
+
+	// Anyone in the channel could click buttons of a public post, so only private posts carry them
+	if flags["public"] == "true" {
+		p.sendMessageFromBot(channelID, "", false, submissionsText)
+		return &model.CommandResponse{}, nil
+	}
+
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		p.sendMessageFromBot(channelID, userID, true, submissionsText)
+		return &model.CommandResponse{}, nil
+	}
+
+	submissionsAttachments := []*model.SlackAttachment{{
+		Text: submissionsText,
+	}}
+
+	for _, submission := range submissions {
+		buttonContext := func(action string) map[string]interface{} {
+			return map[string]interface{}{
+				"action":       action,
+				"actionSecret": p.getConfiguration().EncryptionKey,
+				"submissionID": submission.ID,
+				"number":       strconv.Itoa(int(submission.Number)),
+				"formName":     form.Name,
+			}
+		}
+
+		submissionButton := func(name string, action string) *model.PostAction {
+			return &model.PostAction{
+				Type: model.POST_ACTION_TYPE_BUTTON,
+				Name: name,
+				Integration: &model.PostActionIntegration{
+					URL:     fmt.Sprintf("%s/plugins/netlify/command/submissions", *siteURL),
+					Context: buttonContext(action),
+				},
+			}
+		}
+
+		submissionsAttachments = append(submissionsAttachments, &model.SlackAttachment{
+			Title: fmt.Sprintf("#%v %v", submission.Number, describeSubmitter(submission)),
+			Actions: []*model.PostAction{
+				submissionButton("Mark as spam", ActionMarkSubmissionSpam),
+				submissionButton("Mark as ham", ActionMarkSubmissionHam),
+				submissionButton("Delete", ActionDeleteSubmission),
+			},
+		})
+	}
+
+	p.API.SendEphemeralPost(userID, &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": submissionsAttachments,
+		},
+	})
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleSubmissionsCommandResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	actionToBeTaken, _ := intergrationResponseFromCommand.Context["action"].(string)
+	submissionID, _ := intergrationResponseFromCommand.Context["submissionID"].(string)
+	number, _ := intergrationResponseFromCommand.Context["number"].(string)
+	formName, _ := intergrationResponseFromCommand.Context["formName"].(string)
+
+	var err error
+	var actionMessage string
+
+	switch actionToBeTaken {
+	case ActionMarkSubmissionSpam:
+		err = p.sendNetlifyAPIRequest(userID, http.MethodPut, fmt.Sprintf("/submissions/%v/spam", submissionID), nil, nil, nil)
+		actionMessage = fmt.Sprintf(":no_entry_sign: Submission #%v of **%v** form is marked as spam.", number, formName)
+	case ActionMarkSubmissionHam:
+		err = p.sendNetlifyAPIRequest(userID, http.MethodPut, fmt.Sprintf("/submissions/%v/ham", submissionID), nil, nil, nil)
+		actionMessage = fmt.Sprintf(":white_check_mark: Submission #%v of **%v** form is marked as ham.", number, formName)
+	case ActionDeleteSubmission:
+		// Deleting can't be undone, so it is confirmed first
+		p.sendSubmissionDeleteConfirmation(userID, channelID, submissionID, number, formName)
+		return
+	default:
+		return
+	}
+
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to update submission #%v of **%v** form.\n"+
+				"*Error : %v*", number, formName, err.Error()))
+		return
+	}
+
+	p.sendMessageFromBot(channelID, userID, true, actionMessage)
+}
+
+func (p *Plugin) sendSubmissionDeleteConfirmation(userID string, channelID string, submissionID string, number string, formName string) {
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		p.sendMessageFromBot(channelID, userID, true, "Error! Site URL is not defined in the App")
+		return
+	}
+
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	buttonContext := func(action string) map[string]interface{} {
+		return map[string]interface{}{
+			"action":       action,
+			"actionSecret": actionSecret,
+			"submissionID": submissionID,
+			"number":       number,
+			"formName":     formName,
+		}
+	}
+
+	deleteButton := &model.PostAction{
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Name: "Delete",
+		Integration: &model.PostActionIntegration{
+			URL:     fmt.Sprintf("%s/plugins/netlify/command/submissions-delete", *siteURL),
+			Context: buttonContext(ActionDeleteSubmission),
+		},
+	}
+
+	cancelButton := &model.PostAction{
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Name: "Cancel",
+		Integration: &model.PostActionIntegration{
+			URL:     fmt.Sprintf("%s/plugins/netlify/command/submissions-delete", *siteURL),
+			Context: buttonContext(ActionCancel),
+		},
+	}
+
+	deleteMessageAttachment := &model.SlackAttachment{
+		Title: fmt.Sprintf("Delete submission of %v", formName),
+		Text: fmt.Sprintf(":warning: Are you sure you would like to delete submission #%v of **%v** form?\n"+
+			"It can't be recovered once deleted.", number, formName),
+		Actions: []*model.PostAction{deleteButton, cancelButton},
+	}
+
+	p.API.SendEphemeralPost(userID, &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": []*model.SlackAttachment{deleteMessageAttachment},
+		},
+	})
+}
+
+func (p *Plugin) handleSubmissionDeleteResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+	originalPostID := intergrationResponseFromCommand.PostId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	actionToBeTaken, _ := intergrationResponseFromCommand.Context["action"].(string)
+	submissionID, _ := intergrationResponseFromCommand.Context["submissionID"].(string)
+	number, _ := intergrationResponseFromCommand.Context["number"].(string)
+	formName, _ := intergrationResponseFromCommand.Context["formName"].(string)
+
+	if actionToBeTaken != ActionDeleteSubmission {
+		p.API.UpdateEphemeralPost(userID, &model.Post{
+			Id:        originalPostID,
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message:   fmt.Sprintf(":ok_hand: Submission #%v of **%v** form was not deleted.", number, formName),
+		})
+		return
+	}
+
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+		return
+	}
+
+	deleteSubmissionParams := &netlifyPlumbingModels.DeleteSubmissionParams{
+		SubmissionID: submissionID,
+		Context:      ctx,
+	}
+
+	_, err = netlifyClient.Operations.DeleteSubmission(deleteSubmissionParams, netlifyClientCredentials)
+	if err != nil {
+		p.API.UpdateEphemeralPost(userID, &model.Post{
+			Id:        originalPostID,
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(":exclamation: Failed to delete submission #%v of **%v** form.\n"+
+				"*Error : %v*", number, formName, err.Error()),
+		})
+		return
+	}
+
+	// Submissions can carry personal information, so the deletion is only told to the user like other changes to them
+	p.API.UpdateEphemeralPost(userID, &model.Post{
+		Id:        originalPostID,
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Message:   fmt.Sprintf(":wastebasket: Submission #%v of **%v** form is deleted.", number, formName),
+	})
+}
+
+func (p *Plugin) handleSubmissionsExportCommand(args *model.CommandArgs, arguments []string, flags map[string]string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	if len(arguments) != 1 {
+		p.sendMessageFromBot(channelID, userID, true, "Please mention the form eg. `/netlify submissions export <form> [--since 2020-01-31]`")
+		return &model.CommandResponse{}, nil
+	}
+
+	var since time.Time
+	if sinceFlag, ok := flags["since"]; ok {
+		sinceParsed, err := parseDateFlag(sinceFlag)
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":exclamation: Invalid value of --since flag. %v", err.Error()))
+			return &model.CommandResponse{}, nil
+		}
+		since = sinceParsed
+	}
+
+	form, err := p.getFormFromCommandArgument(userID, arguments[0])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the form\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Submissions are sorted most recent first, so paging stops at the first one older than since
+	var submissions []*netlifyModels.Submission
+	var isOlderThanSince bool = false
+	for page := 1; isOlderThanSince == false; page++ {
+		submissionsOfPage, err := p.listFormSubmissions(userID, form.ID, page, SubmissionsPerPage)
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Failed to get submissions of **%v** form.\n"+
+					"*Error : %v*", form.Name, err.Error()))
+			return &model.CommandResponse{}, nil
+		}
+
+		for _, submission := range submissionsOfPage {
+			submittedAt, err := time.Parse(NetlifyDateLayout, submission.CreatedAt)
+			if err == nil && !since.IsZero() && submittedAt.Before(since) {
+				isOlderThanSince = true
+				break
+			}
+
+			submissions = append(submissions, submission)
+		}
+
+		// A page smaller than asked for means there are no older submissions
+		if len(submissionsOfPage) < SubmissionsPerPage {
+			break
+		}
+	}
+
+	if len(submissions) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: **%v** form has no submissions to export", form.Name))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Fields can differ across submissions as the form changes, so columns are all the fields ever submitted
+	fieldNamesFound := map[string]bool{}
+	var fieldNames []string
+	for _, submission := range submissions {
+		submissionData, _ := submission.Data.(map[string]interface{})
+		for fieldName := range submissionData {
+			if fieldNamesFound[fieldName] == false {
+				fieldNamesFound[fieldName] = true
+				fieldNames = append(fieldNames, fieldName)
+			}
+		}
+	}
+	sort.Strings(fieldNames)
+
+	var submissionsInCSV bytes.Buffer
+	csvWriter := csv.NewWriter(&submissionsInCSV)
+
+	csvWriter.Write(escapeCSVRow(append([]string{"Number", "Submitted at", "Submission ID"}, fieldNames...)))
+
+	for _, submission := range submissions {
+		submissionData, _ := submission.Data.(map[string]interface{})
+
+		csvRow := []string{strconv.Itoa(int(submission.Number)), submission.CreatedAt, submission.ID}
+		for _, fieldName := range fieldNames {
+			csvRow = append(csvRow, formatSubmissionFieldValue(submissionData[fieldName]))
+		}
+
+		csvWriter.Write(escapeCSVRow(csvRow))
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to export submissions of **%v** form.\n"+
+				"*Error : %v*", form.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	fileName := fmt.Sprintf("%v-submissions-%v.csv", form.Name, time.Now().Format("2006-01-02-150405"))
+
+	fileInfo, appErr := p.API.UploadFile(submissionsInCSV.Bytes(), channelID, fileName)
+	if appErr != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to upload export of **%v** form submissions.\n"+
+				"*Error : %v*", form.Name, appErr.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	exportMessage := fmt.Sprintf(":page_facing_up: %v exported %v submissions of **%v** form.", p.getUserMention(userID), len(submissions), form.Name)
+	if !since.IsZero() {
+		exportMessage = fmt.Sprintf(":page_facing_up: %v exported %v submissions of **%v** form since %v.",
+			p.getUserMention(userID), len(submissions), form.Name, flags["since"])
+	}
+
+	_, appErr = p.API.CreatePost(&model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Message:   exportMessage,
+		FileIds:   []string{fileInfo.Id},
+	})
+	if appErr != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to post export of **%v** form submissions.\n"+
+				"*Error : %v*", form.Name, appErr.Error()))
+	}
+
+	return &model.CommandResponse{}, nil
+}
+
+// listFormSubmissions returns a single page of submissions of a form, most recent first.
+// Netlify library client doesn't support pagination of submissions, hence calling the api directly.
+func (p *Plugin) listFormSubmissions(userID string, formID string, page int, perPage int) ([]*netlifyModels.Submission, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	var submissions []*netlifyModels.Submission
+	err := p.sendNetlifyAPIRequest(userID, http.MethodGet, fmt.Sprintf("/forms/%v/submissions", formID), query, nil, &submissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return submissions, nil
+}
+
+// getFormFromCommandArgument returns the form of the user whose id or name is same as passed in the command.
+// Since forms of different sites can share a name, name must be unique across all the sites.
+func (p *Plugin) getFormFromCommandArgument(userID string, formNameOrID string) (*netlifyModels.Form, error) {
+	// Get the Netlify library client for interacting with netlify api
+	netlifyClient, ctx := p.getNetlifyClient()
+
+	// Get Netlify credentials
+	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	listFormsParams := &netlifyPlumbingModels.ListFormsParams{
+		Context: ctx,
+	}
+
+	listFormsResponse, err := netlifyClient.Operations.ListForms(listFormsParams, netlifyCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	var formsFound []*netlifyModels.Form
+	for _, form := range listFormsResponse.GetPayload() {
+		if form.ID == formNameOrID {
+			return form, nil
+		}
+		if form.Name == formNameOrID {
+			formsFound = append(formsFound, form)
+		}
+	}
+
+	if len(formsFound) > 1 {
+		return nil, fmt.Errorf("More than one site has a form named %v, please use the form id shown by `/netlify forms <site>`", formNameOrID)
+	}
+
+	if len(formsFound) == 0 {
+		return nil, fmt.Errorf("No form found by the name or id %v", formNameOrID)
+	}
+
+	return formsFound[0], nil
+}
+
+// formatSubmissionFieldValue converts value of a submitted field to text, fields like checkboxes are submitted as lists
+func formatSubmissionFieldValue(value interface{}) string {
+	switch fieldValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return fieldValue
+	default:
+		valueInBytes, err := json.Marshal(fieldValue)
+		if err != nil {
+			return fmt.Sprint(fieldValue)
+		}
+		return string(valueInBytes)
+	}
+}
+
+// describeSubmitter returns name and email of whoever made the submission, whichever are present
+func describeSubmitter(submission *netlifyModels.Submission) string {
+	var submitter []string
+	if len(submission.Name) != 0 {
+		submitter = append(submitter, submission.Name)
+	}
+	if len(submission.Email) != 0 {
+		submitter = append(submitter, submission.Email)
+	}
+
+	return strings.Join(submitter, " - ")
+}
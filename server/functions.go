@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// NetlifyDeployFunction is a serverless function of a deploy as described in function metadata of the deploy
+type NetlifyDeployFunction struct {
+	Name    string `json:"n"`
+	Runtime string `json:"r"`
+	Size    int64  `json:"s"`
+	Sha     string `json:"id"`
+}
+
+func (p *Plugin) handleFunctionsCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	if len(parameters) != 1 && len(parameters) != 2 {
+		p.sendMessageFromBot(channelID, userID, true, "Please mention the site and optionally a deploy eg. `/netlify functions <site> [deployID]`")
+		return &model.CommandResponse{}, nil
+	}
+
+	site, err := p.resolveSite(userID, parameters[0])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Published deploy is looked into unless a deploy is chosen
+	var deployID string
+	if len(parameters) == 2 {
+		deployID = parameters[1]
+	} else if site.PublishedDeploy != nil {
+		deployID = site.PublishedDeploy.ID
+	}
+
+	if len(deployID) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: **%v** site has no published deploy", site.Name))
+		return &model.CommandResponse{}, nil
+	}
+
+	deploy, err := p.getDeployWithFunctions(userID, deployID)
+	if err != nil || deploy.SiteID != site.ID {
+		errorMessage := "Deploy doesn't belong to the site"
+		if err != nil {
+			errorMessage = err.Error()
+		}
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get %v deploy of **%v** site.\n"+
+				"*Error : %v*", deployID, site.Name, errorMessage))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Not finding the previous deploy shouldn't stop listing the functions
+	previousDeploy, err := p.getPreviousDeploy(userID, deploy)
+	if err != nil {
+		p.API.LogWarn("Failed to get previous deploy for comparing functions", "deploy_id", deploy.ID, "error", err.Error())
+	}
+
+	functionsMessage := fmt.Sprintf("#### :zap: Functions of %v deploy of %v\n", deploy.ID, site.Name)
+	if previousDeploy != nil {
+		functionsMessage = functionsMessage + fmt.Sprintf("Compared with the previous %v deploy %v created on %v\n",
+			deploy.Context, previousDeploy.ID, formatNetlifyDate(previousDeploy.CreatedAt))
+	} else {
+		functionsMessage = functionsMessage + "*There is no previous deploy to compare with*\n"
+	}
+
+	if len(deploy.AvailableFunctions) == 0 && (previousDeploy == nil || len(previousDeploy.AvailableFunctions) == 0) {
+		p.sendMessageFromBot(channelID, "", false, functionsMessage+"\n*This deploy has no functions*")
+		return &model.CommandResponse{}, nil
+	}
+
+	previousFunctions := map[string]*NetlifyDeployFunction{}
+	if previousDeploy != nil {
+		for _, function := range previousDeploy.AvailableFunctions {
+			previousFunctions[function.Name] = function
+		}
+	}
+
+	// Create a table with just the header, rows will fill up in the loop
+	var functionsMarkdownTable string = MarkdownFunctionTableHeader
+	var addedFunctions, removedFunctions int = 0, 0
+
+	sort.Slice(deploy.AvailableFunctions, func(i, j int) bool {
+		return deploy.AvailableFunctions[i].Name < deploy.AvailableFunctions[j].Name
+	})
+
+	currentFunctions := map[string]bool{}
+	for _, function := range deploy.AvailableFunctions {
+		currentFunctions[function.Name] = true
+
+		var change string = "-"
+		if previousDeploy != nil {
+			previousFunction, existedBefore := previousFunctions[function.Name]
+			if existedBefore == false {
+				change = ":new: Added"
+				addedFunctions = addedFunctions + 1
+			} else if previousFunction.Sha != function.Sha {
+				change = ":pencil2: Changed"
+			}
+		}
+
+		functionsMarkdownTable = fmt.Sprintf("%v\n| %v | %v | %v | %v |", functionsMarkdownTable,
+			function.Name, function.Runtime, describeSize(function.Size), change)
+	}
+
+	if previousDeploy != nil {
+		for _, function := range previousDeploy.AvailableFunctions {
+			if currentFunctions[function.Name] == true {
+				continue
+			}
+
+			removedFunctions = removedFunctions + 1
+			functionsMarkdownTable = fmt.Sprintf("%v\n| ~~%v~~ | %v | %v | :wastebasket: Removed |", functionsMarkdownTable,
+				function.Name, function.Runtime, describeSize(function.Size))
+		}
+	}
+
+	if addedFunctions != 0 || removedFunctions != 0 {
+		functionsMessage = functionsMessage + fmt.Sprintf(":warning: %v functions were added and %v removed, please make sure these backend changes are expected.\n",
+			addedFunctions, removedFunctions)
+	}
+
+	p.sendMessageFromBot(channelID, "", false, functionsMessage+functionsMarkdownTable)
+
+	return &model.CommandResponse{}, nil
+}
+
+// getDeployWithFunctions returns the deploy along with its function metadata, which netlify library model doesn't have
+func (p *Plugin) getDeployWithFunctions(userID string, deployID string) (*NetlifyDeploy, error) {
+	deploy := &NetlifyDeploy{}
+	err := p.sendNetlifyAPIRequest(userID, http.MethodGet, fmt.Sprintf("/deploys/%v", deployID), nil, nil, deploy)
+	if err != nil {
+		return nil, err
+	}
+
+	return deploy, nil
+}
+
+// getPreviousDeploy returns the successful deploy of the same context created just before the given deploy, nil if there is none
+func (p *Plugin) getPreviousDeploy(userID string, deploy *NetlifyDeploy) (*NetlifyDeploy, error) {
+	deployCreatedAt, err := time.Parse(NetlifyDateLayout, deploy.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	deploysQuery := url.Values{}
+	deploysQuery.Set("state", NetlifyEventStateDeployCreated)
+
+	deploys, err := p.listSiteDeploys(userID, deploy.SiteID, 1, FunctionsPreviousDeploySearchLimit, deploysQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deploys are sorted newest first
+	for _, siteDeploy := range deploys {
+		if siteDeploy.ID == deploy.ID || siteDeploy.Context != deploy.Context || siteDeploy.State != NetlifyEventStateDeployCreated {
+			continue
+		}
+
+		siteDeployCreatedAt, err := time.Parse(NetlifyDateLayout, siteDeploy.CreatedAt)
+		if err != nil || !siteDeployCreatedAt.Before(deployCreatedAt) {
+			continue
+		}
+
+		// Listed deploys don't carry function metadata
+		return p.getDeployWithFunctions(userID, siteDeploy.ID)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
+)
+
+func (p *Plugin) handleHooksCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	if len(parameters) != 1 {
+		p.sendMessageFromBot(channelID, userID, true, "Please mention the site eg. `/netlify hooks <site>`")
+		return &model.CommandResponse{}, nil
+	}
+
+	site, err := p.resolveSiteForChange(userID, parameters[0])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	hooksPost, err := p.getNotificationHooksPost(userID, channelID, site.ID, site.Name)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get notification hooks of **%v** site.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	p.API.SendEphemeralPost(userID, hooksPost)
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleHooksCommandResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+	originalPostID := intergrationResponseFromCommand.PostId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	actionToBeTaken, _ := intergrationResponseFromCommand.Context["action"].(string)
+	siteID, _ := intergrationResponseFromCommand.Context["siteID"].(string)
+	siteName, _ := intergrationResponseFromCommand.Context["siteName"].(string)
+	hookID, _ := intergrationResponseFromCommand.Context["hookID"].(string)
+
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+		return
+	}
+
+	getHookParams := &netlifyPlumbingModels.GetHookParams{
+		HookID:  hookID,
+		Context: ctx,
+	}
+
+	getHookResponse, err := netlifyClient.Operations.GetHook(getHookParams, netlifyClientCredentials)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find %v notification hook of **%v** site.\n"+
+				"*Error : %v*", hookID, siteName, err.Error()))
+		return
+	}
+
+	hook := getHookResponse.GetPayload()
+
+	// Deleting can't be undone, so it is confirmed first
+	if actionToBeTaken == ActionDeleteHook {
+		p.sendNotificationHookDeleteConfirmation(userID, channelID, siteID, siteName, hook, originalPostID)
+		return
+	}
+
+	var auditAction, actionDescription string
+
+	switch actionToBeTaken {
+	case ActionEnableHook:
+		enableHookParams := &netlifyPlumbingModels.EnableHookParams{
+			HookID:  hookID,
+			Context: ctx,
+		}
+
+		_, err = netlifyClient.Operations.EnableHook(enableHookParams, netlifyClientCredentials)
+		auditAction, actionDescription = "hook_enable", "enabled"
+	case ActionDisableHook:
+		hook.Disabled = true
+		updateHookParams := &netlifyPlumbingModels.UpdateHookParams{
+			HookID:  hookID,
+			Hook:    hook,
+			Context: ctx,
+		}
+
+		_, err = netlifyClient.Operations.UpdateHook(updateHookParams, netlifyClientCredentials)
+		auditAction, actionDescription = "hook_disable", "disabled"
+	default:
+		return
+	}
+
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to update %v notification hook of **%v** site.\n"+
+				"*Error : %v*", hookID, siteName, err.Error()))
+		return
+	}
+
+	hookDescription := fmt.Sprintf("%v %v hook for %v event", hook.ID, hook.Type, hook.Event)
+
+	err = p.recordAuditEvent(siteID, userID, auditAction, hookDescription)
+	if err != nil {
+		p.API.LogError("Failed to save audit record of notification hook change", "site_id", siteID, "error", err.Error())
+	}
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(":bell: %v %v the %v notification hook for `%v` event of **%v** site, sending to %v.",
+		p.getUserMention(userID), actionDescription, hook.Type, hook.Event, siteName, p.describeNotificationHookDestination(hook)))
+
+	// Refresh the hooks post so it reflects the change
+	hooksPost, err := p.getNotificationHooksPost(userID, channelID, siteID, siteName)
+	if err != nil {
+		return
+	}
+
+	hooksPost.Id = originalPostID
+	p.API.UpdateEphemeralPost(userID, hooksPost)
+}
+
+func (p *Plugin) sendNotificationHookDeleteConfirmation(userID string, channelID string, siteID string, siteName string, hook *netlifyModels.Hook, hooksPostID string) {
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		p.sendMessageFromBot(channelID, userID, true, "Error! Site URL is not defined in the App")
+		return
+	}
+
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	buttonContext := func(action string) map[string]interface{} {
+		return map[string]interface{}{
+			"action":       action,
+			"actionSecret": actionSecret,
+			"siteID":       siteID,
+			"siteName":     siteName,
+			"hookID":       hook.ID,
+			"hooksPostID":  hooksPostID,
+		}
+	}
+
+	deleteButton := &model.PostAction{
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Name: "Delete",
+		Integration: &model.PostActionIntegration{
+			URL:     fmt.Sprintf("%s/plugins/netlify/command/hooks-delete", *siteURL),
+			Context: buttonContext(ActionDeleteHook),
+		},
+	}
+
+	cancelButton := &model.PostAction{
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Name: "Cancel",
+		Integration: &model.PostActionIntegration{
+			URL:     fmt.Sprintf("%s/plugins/netlify/command/hooks-delete", *siteURL),
+			Context: buttonContext(ActionCancel),
+		},
+	}
+
+	deleteMessageAttachment := &model.SlackAttachment{
+		Title: fmt.Sprintf("Delete notification hook of %v", siteName),
+		Text: fmt.Sprintf(":warning: Are you sure you would like to delete the %v notification hook for `%v` event, sending to %v?\n"+
+			"The event will no longer be notified there.", hook.Type, hook.Event, p.describeNotificationHookDestination(hook)),
+		Actions: []*model.PostAction{deleteButton, cancelButton},
+	}
+
+	p.API.SendEphemeralPost(userID, &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": []*model.SlackAttachment{deleteMessageAttachment},
+		},
+	})
+}
+
+func (p *Plugin) handleNotificationHookDeleteResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+	originalPostID := intergrationResponseFromCommand.PostId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	actionToBeTaken, _ := intergrationResponseFromCommand.Context["action"].(string)
+	siteID, _ := intergrationResponseFromCommand.Context["siteID"].(string)
+	siteName, _ := intergrationResponseFromCommand.Context["siteName"].(string)
+	hookID, _ := intergrationResponseFromCommand.Context["hookID"].(string)
+	hooksPostID, _ := intergrationResponseFromCommand.Context["hooksPostID"].(string)
+
+	if actionToBeTaken != ActionDeleteHook {
+		p.API.UpdateEphemeralPost(userID, &model.Post{
+			Id:        originalPostID,
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message:   fmt.Sprintf(":ok_hand: %v notification hook of **%v** site was not deleted.", hookID, siteName),
+		})
+		return
+	}
+
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+		return
+	}
+
+	// Get the hook first, so the channel knows which one went away
+	getHookParams := &netlifyPlumbingModels.GetHookParams{
+		HookID:  hookID,
+		Context: ctx,
+	}
+
+	getHookResponse, err := netlifyClient.Operations.GetHook(getHookParams, netlifyClientCredentials)
+	if err != nil {
+		p.API.UpdateEphemeralPost(userID, &model.Post{
+			Id:        originalPostID,
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(":exclamation: Failed to find %v notification hook of **%v** site.\n"+
+				"*Error : %v*", hookID, siteName, err.Error()),
+		})
+		return
+	}
+
+	hook := getHookResponse.GetPayload()
+
+	deleteHookParams := &netlifyPlumbingModels.DeleteHookBySiteIDParams{
+		HookID:  hookID,
+		Context: ctx,
+	}
+
+	_, err = netlifyClient.Operations.DeleteHookBySiteID(deleteHookParams, netlifyClientCredentials)
+	if err != nil {
+		p.API.UpdateEphemeralPost(userID, &model.Post{
+			Id:        originalPostID,
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message: fmt.Sprintf(":exclamation: Failed to delete %v notification hook of **%v** site.\n"+
+				"*Error : %v*", hookID, siteName, err.Error()),
+		})
+		return
+	}
+
+	err = p.recordAuditEvent(siteID, userID, "hook_delete", fmt.Sprintf("%v %v hook for %v event", hook.ID, hook.Type, hook.Event))
+	if err != nil {
+		p.API.LogError("Failed to save audit record of notification hook change", "site_id", siteID, "error", err.Error())
+	}
+
+	p.API.DeleteEphemeralPost(userID, originalPostID)
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(":bell: %v deleted the %v notification hook for `%v` event of **%v** site, sending to %v.",
+		p.getUserMention(userID), hook.Type, hook.Event, siteName, p.describeNotificationHookDestination(hook)))
+
+	// Refresh the hooks post the deletion was asked from, so the deleted hook goes away
+	if len(hooksPostID) == 0 {
+		return
+	}
+
+	hooksPost, err := p.getNotificationHooksPost(userID, channelID, siteID, siteName)
+	if err != nil {
+		return
+	}
+
+	hooksPost.Id = hooksPostID
+	p.API.UpdateEphemeralPost(userID, hooksPost)
+}
+
+// getNotificationHooksPost returns a post listing every notification hook of the site, along with buttons to enable, disable or delete it
+func (p *Plugin) getNotificationHooksPost(userID string, channelID string, siteID string, siteName string) (*model.Post, error) {
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		return nil, fmt.Errorf("Site URL is not defined in the App")
+	}
+
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	listHooksBySiteIDParams := &netlifyPlumbingModels.ListHooksBySiteIDParams{
+		SiteID:  siteID,
+		Context: ctx,
+	}
+
+	listHooksBySiteIDResponse, err := netlifyClient.Operations.ListHooksBySiteID(listHooksBySiteIDParams, netlifyClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := listHooksBySiteIDResponse.GetPayload()
+
+	if len(hooks) == 0 {
+		return &model.Post{
+			UserId:    p.BotUserID,
+			ChannelId: channelID,
+			Message:   fmt.Sprintf(":bell: **%v** site doesn't have any notification hooks.", siteName),
+		}, nil
+	}
+
+	// Hooks of the same event are kept together
+	sort.Slice(hooks, func(i, j int) bool {
+		if hooks[i].Event != hooks[j].Event {
+			return hooks[i].Event < hooks[j].Event
+		}
+		return hooks[i].Type < hooks[j].Type
+	})
+
+	var disabledHooks int = 0
+	for _, hook := range hooks {
+		if hook.Disabled == true {
+			disabledHooks = disabledHooks + 1
+		}
+	}
+
+	hooksAttachments := []*model.SlackAttachment{{
+		Text: fmt.Sprintf("#### Notification hooks of %v\n"+
+			"%v hooks, of which %v are disabled", siteName, len(hooks), disabledHooks),
+	}}
+
+	for _, hook := range hooks {
+		buttonContext := func(action string) map[string]interface{} {
+			return map[string]interface{}{
+				"action":       action,
+				"actionSecret": p.getConfiguration().EncryptionKey,
+				"siteID":       siteID,
+				"siteName":     siteName,
+				"hookID":       hook.ID,
+			}
+		}
+
+		hookButton := func(name string, action string) *model.PostAction {
+			return &model.PostAction{
+				Type: model.POST_ACTION_TYPE_BUTTON,
+				Name: name,
+				Integration: &model.PostActionIntegration{
+					URL:     fmt.Sprintf("%s/plugins/netlify/command/hooks", *siteURL),
+					Context: buttonContext(action),
+				},
+			}
+		}
+
+		var hookState string = ":white_check_mark: Enabled"
+		toggleButton := hookButton("Disable", ActionDisableHook)
+		if hook.Disabled == true {
+			hookState = ":no_entry_sign: Disabled"
+			toggleButton = hookButton("Enable", ActionEnableHook)
+		}
+
+		hooksAttachments = append(hooksAttachments, &model.SlackAttachment{
+			Title: fmt.Sprintf("%v on %v", hook.Type, hook.Event),
+			Text: fmt.Sprintf("*Sends to* : %v\n"+
+				"*State* : %v\n"+
+				"*Created at* : %v\n"+
+				"*Hook ID* : %v",
+				p.describeNotificationHookDestination(hook), hookState, formatNetlifyDate(hook.CreatedAt), hook.ID),
+			Actions: []*model.PostAction{toggleButton, hookButton("Delete", ActionDeleteHook)},
+		})
+	}
+
+	return &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": hooksAttachments,
+		},
+	}, nil
+}
+
+// describeNotificationHookDestination returns where the hook sends notifications to.
+// URLs of hooks usually carry secrets, so only their host is shown.
+func (p *Plugin) describeNotificationHookDestination(hook *netlifyModels.Hook) string {
+	hookData, _ := hook.Data.(map[string]interface{})
+
+	switch hook.Type {
+	case NetlifyHookTypeEmail:
+		if email, _ := hookData["email"].(string); len(email) != 0 {
+			return email
+		}
+	case NetlifyHookTypeURL, NetlifyHookTypeSlack:
+		hookURL, _ := hookData["url"].(string)
+
+		siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+		if siteURL != nil && hookURL == fmt.Sprintf("%v/plugins/netlify/webhook/%v", *siteURL, p.getConfiguration().WebhookSecret) {
+			return "this Mattermost, for `/netlify subscribe`"
+		}
+
+		parsedHookURL, err := url.Parse(hookURL)
+		if err == nil && len(parsedHookURL.Host) != 0 {
+			return fmt.Sprintf("`%v://%v/••••••••`", parsedHookURL.Scheme, parsedHookURL.Host)
+		}
+	case NetlifyHookTypeGithub:
+		return "GitHub commit statuses and pull request comments"
+	}
+
+	return "-"
+}
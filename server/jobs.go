@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// scheduleClusterJob runs the job every interval until the plugin is deactivated.
+// On a cluster every server schedules the job, but a lock in KV store lets only one of them run it per interval.
+func (p *Plugin) scheduleClusterJob(jobName string, interval time.Duration, job func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if p.acquireClusterJobLock(jobName, interval) == true {
+				job()
+			}
+
+			select {
+			case <-ticker.C:
+			case <-p.stopBackgroundJobs:
+				return
+			}
+		}
+	}()
+}
+
+// acquireClusterJobLock returns true if this server gets to run the job for the coming interval.
+// Lock holds the time till which it is held, and is taken over only by comparing with that value so two servers can't both take it.
+func (p *Plugin) acquireClusterJobLock(jobName string, interval time.Duration) bool {
+	jobLockIdentifier := jobName + NetlifyJobLockKVIdentifier
+
+	lockedUntilInBytes, appErr := p.API.KVGet(jobLockIdentifier)
+	if appErr != nil {
+		return false
+	}
+
+	now := model.GetMillis()
+
+	if lockedUntilInBytes != nil {
+		lockedUntil, err := strconv.ParseInt(string(lockedUntilInBytes), 10, 64)
+		if err == nil && now < lockedUntil {
+			return false
+		}
+	}
+
+	// A little less than the interval, so delays in ticking of this server don't skip a run
+	lockedUntil := now + int64(interval/time.Millisecond) - int64(ClusterJobLockMargin/time.Millisecond)
+
+	isLockAcquired, appErr := p.API.KVCompareAndSet(jobLockIdentifier, lockedUntilInBytes, []byte(strconv.FormatInt(lockedUntil, 10)))
+	if appErr != nil {
+		return false
+	}
+
+	return isLockAcquired
+}
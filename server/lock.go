@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
+)
+
+// DeployLock holds the information of who locked production publishing of a site and why
+type DeployLock struct {
+	DeployID string `json:"deploy_id"`
+	UserID   string `json:"user_id"`
+	Reason   string `json:"reason"`
+	LockedAt int64  `json:"locked_at"`
+}
+
+func (p *Plugin) handleLockCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	// Site name or id is required, everything after it is the reason of locking
+	if len(parameters) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, "Please mention the site you want to lock eg. `/netlify lock <site> [reason]`")
+		return &model.CommandResponse{}, nil
+	}
+
+	site, err := p.resolveSiteForChange(userID, parameters[0])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// If site is already locked from Mattermost, tell who did it
+	existingLock, err := p.getCurrentDeployLockForSite(site)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get lock information of **%v** site\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	if existingLock != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":lock: **%v** site is already locked.\n%v",
+			site.Name, p.describeDeployLock(existingLock)))
+		return &model.CommandResponse{}, nil
+	}
+
+	if site.PublishedDeploy == nil || len(site.PublishedDeploy.ID) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":white_flag: **%v** site has no published deploy to lock", site.Name))
+		return &model.CommandResponse{}, nil
+	}
+
+	reason := strings.Trim(strings.Join(parameters[1:], " "), "\"")
+
+	// Get the Netlify library client for interacting with netlify api
+	netlifyClient, ctx := p.getNetlifyClient()
+
+	// Get Netlify credentials
+	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	lockDeployParams := &netlifyPlumbingModels.LockDeployParams{
+		DeployID: site.PublishedDeploy.ID,
+		Context:  ctx,
+	}
+
+	_, err = netlifyClient.Operations.LockDeploy(lockDeployParams, netlifyCredentials)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to lock **%v** site.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	deployLock := &DeployLock{
+		DeployID: site.PublishedDeploy.ID,
+		UserID:   userID,
+		Reason:   reason,
+		LockedAt: model.GetMillis(),
+	}
+
+	err = p.setDeployLockForSite(site.ID, deployLock)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":grey_exclamation: **%v** site is locked at Netlify, but the reason could not be saved.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(
+		":lock: Production publishing of **%v** site is now locked at deploy %v.\n%v\n"+
+			"New deploys will be built but not published until someone runs `/netlify unlock %v`.",
+		site.Name, site.PublishedDeploy.ID, p.describeDeployLock(deployLock), site.Name))
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleUnlockCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	if len(parameters) != 1 {
+		p.sendMessageFromBot(channelID, userID, true, "Please mention the site you want to unlock eg. `/netlify unlock <site>`")
+		return &model.CommandResponse{}, nil
+	}
+
+	site, err := p.resolveSiteForChange(userID, parameters[0])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	existingLock, err := p.getCurrentDeployLockForSite(site)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get lock information of **%v** site\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Site could have been locked from Netlify app too, in which case we only know of the published deploy
+	var deployIDToUnlock string
+	if existingLock != nil {
+		deployIDToUnlock = existingLock.DeployID
+	} else if site.PublishedDeploy != nil && site.PublishedDeploy.Locked == true {
+		deployIDToUnlock = site.PublishedDeploy.ID
+	} else {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":unlock: **%v** site is not locked", site.Name))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Get the Netlify library client for interacting with netlify api
+	netlifyClient, ctx := p.getNetlifyClient()
+
+	// Get Netlify credentials
+	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	unlockDeployParams := &netlifyPlumbingModels.UnlockDeployParams{
+		DeployID: deployIDToUnlock,
+		Context:  ctx,
+	}
+
+	_, err = netlifyClient.Operations.UnlockDeploy(unlockDeployParams, netlifyCredentials)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to unlock **%v** site.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	appErr := p.API.KVDelete(site.ID + NetlifyDeployLockKVIdentifier)
+	if appErr != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":grey_exclamation: **%v** site is unlocked at Netlify, but the lock information could not be cleared.\n"+
+				"*Error : %v*", site.Name, appErr.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	unlockMessage := fmt.Sprintf(":unlock: %v unlocked production publishing of **%v** site.", p.getUserMention(userID), site.Name)
+	if existingLock != nil {
+		unlockMessage = fmt.Sprintf("%v\n*Earlier lock* : %v", unlockMessage, p.describeDeployLock(existingLock))
+	}
+
+	p.sendMessageFromBot(channelID, "", false, unlockMessage)
+
+	return &model.CommandResponse{}, nil
+}
+
+// describeDeployLock returns a sentence telling who locked the site, when and why
+func (p *Plugin) describeDeployLock(deployLock *DeployLock) string {
+	reason := "*no reason was given*"
+	if len(deployLock.Reason) != 0 {
+		reason = deployLock.Reason
+	}
+
+	lockedAt := time.Unix(0, deployLock.LockedAt*int64(time.Millisecond)).Format(time.RFC822)
+
+	return fmt.Sprintf("Locked by %v on %v, reason : %v", p.getUserMention(deployLock.UserID), lockedAt, reason)
+}
+
+// getDeployLockWarning returns a warning to be shown before deploying or rolling back a locked site, empty if site isn't locked.
+// Sites locked from Netlify app have no lock information in Mattermost, so the published deploy of the site is checked for them.
+func (p *Plugin) getDeployLockWarning(userID string, siteID string, siteName string) string {
+	var deployLock *DeployLock
+	site, err := p.getSiteByID(userID, siteID)
+	if err == nil {
+		deployLock, err = p.getCurrentDeployLockForSite(site)
+	} else {
+		deployLock, err = p.getDeployLockForSite(siteID)
+	}
+
+	if err == nil && deployLock != nil {
+		return fmt.Sprintf(":warning: Production publishing of **%v** site is locked. %v\n"+
+			"Until it is unlocked with `/netlify unlock %v`, new deploys will not be published automatically.", siteName, p.describeDeployLock(deployLock), siteName)
+	}
+
+	if site == nil || site.PublishedDeploy == nil || site.PublishedDeploy.Locked == false {
+		return ""
+	}
+
+	return fmt.Sprintf(":warning: Production publishing of **%v** site is locked from Netlify app at deploy %v.\n"+
+		"Until it is unlocked with `/netlify unlock %v`, new deploys will not be published automatically.", siteName, site.PublishedDeploy.ID, siteName)
+}
+
+func (p *Plugin) setDeployLockForSite(siteID string, deployLock *DeployLock) error {
+	// Unique identifier
+	deployLockIdentifier := siteID + NetlifyDeployLockKVIdentifier
+
+	deployLockInBytes, err := json.Marshal(deployLock)
+	if err != nil {
+		return err
+	}
+
+	appErr := p.API.KVSet(deployLockIdentifier, deployLockInBytes)
+	if appErr != nil {
+		return appErr
+	}
+
+	return nil
+}
+
+// getCurrentDeployLockForSite returns the lock information of a site saved in Mattermost, as long as its published deploy is still locked.
+// Site can be unlocked from Netlify app too, in which case the saved lock information is stale and is cleared.
+func (p *Plugin) getCurrentDeployLockForSite(site *netlifyModels.Site) (*DeployLock, error) {
+	deployLock, err := p.getDeployLockForSite(site.ID)
+	if err != nil || deployLock == nil {
+		return deployLock, err
+	}
+
+	if site.PublishedDeploy != nil && site.PublishedDeploy.Locked == false {
+		appErr := p.API.KVDelete(site.ID + NetlifyDeployLockKVIdentifier)
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		return nil, nil
+	}
+
+	return deployLock, nil
+}
+
+// getDeployLockForSite returns the lock information of a site, nil if site was not locked from Mattermost
+func (p *Plugin) getDeployLockForSite(siteID string) (*DeployLock, error) {
+	deployLockIdentifier := siteID + NetlifyDeployLockKVIdentifier
+
+	deployLockInBytes, appErr := p.API.KVGet(deployLockIdentifier)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// It returns nil if value is not found
+	if deployLockInBytes == nil {
+		return nil, nil
+	}
+
+	deployLock := &DeployLock{}
+	err := json.Unmarshal(deployLockInBytes, deployLock)
+	if err != nil {
+		return nil, err
+	}
+
+	return deployLock, nil
+}
@@ -71,6 +71,38 @@ const manifestStr = `
         "help_text": "This Secret key will be used to uniquely identify incoming webhook requests from Netlify.",
         "placeholder": "Generate the key and store before connecting the account",
         "default": null
+      },
+      {
+        "key": "EnableCertificateWatchdog",
+        "display_name": "Warn About Expiring Certificates",
+        "type": "bool",
+        "help_text": "When true, channels subscribed to a site are warned when its SSL certificate is about to expire or fails to provision.",
+        "placeholder": "",
+        "default": true
+      },
+      {
+        "key": "CertificateExpiryWarningDays",
+        "display_name": "Certificate Expiry Warning Days",
+        "type": "text",
+        "help_text": "Comma separated number of days before expiry of an SSL certificate at which subscribed channels are warned.",
+        "placeholder": "Eg. 14,3",
+        "default": "14,3"
+      },
+      {
+        "key": "AdminRoles",
+        "display_name": "Roles Allowed to Run Admin Commands",
+        "type": "text",
+        "help_text": "Comma separated Mattermost roles which besides system admins are allowed to run destructive commands like deleting a site or managing Netlify team members.",
+        "placeholder": "Eg. team_admin,channel_admin",
+        "default": null
+      },
+      {
+        "key": "UsageAlertChannelID",
+        "display_name": "Usage Alert Channel ID",
+        "type": "text",
+        "help_text": "ID of the channel which is alerted when bandwidth or build minutes used by a Netlify team reach 80% and 100% of its plan limits. Leave empty to turn off the alerts.",
+        "placeholder": "Eg. ID of the channel alerts are posted to",
+        "default": null
       }
     ]
   }
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+)
+
+// isUserAllowedAdminCommands returns true if the user may run commands which are destructive or change Netlify accounts.
+// System admins always can, others need one of the configured roles at system, team or channel level.
+func (p *Plugin) isUserAllowedAdminCommands(userID string, teamID string, channelID string) bool {
+	user, appErr := p.API.GetUser(userID)
+	if appErr != nil {
+		return false
+	}
+
+	userRoles := strings.Fields(user.Roles)
+
+	if len(teamID) != 0 {
+		teamMember, appErr := p.API.GetTeamMember(teamID, userID)
+		if appErr == nil {
+			userRoles = append(userRoles, strings.Fields(teamMember.Roles)...)
+		}
+	}
+
+	if len(channelID) != 0 {
+		channelMember, appErr := p.API.GetChannelMember(channelID, userID)
+		if appErr == nil {
+			userRoles = append(userRoles, strings.Fields(channelMember.Roles)...)
+		}
+	}
+
+	allowedRoles := append(p.getConfiguration().getAdminRoles(), model.SYSTEM_ADMIN_ROLE_ID)
+
+	for _, userRole := range userRoles {
+		for _, allowedRole := range allowedRoles {
+			if userRole == allowedRole {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// getAdminRoles returns the Mattermost roles which besides system admins are allowed to run admin commands
+func (c *configuration) getAdminRoles() []string {
+	var adminRoles []string
+	for _, adminRole := range strings.Split(c.AdminRoles, ",") {
+		adminRole = strings.TrimSpace(adminRole)
+		if len(adminRole) != 0 {
+			adminRoles = append(adminRoles, adminRole)
+		}
+	}
+
+	return adminRoles
+}
@@ -23,6 +23,9 @@ type Plugin struct {
 	// configuration is the active plugin configuration. Consult getConfiguration and
 	// setConfiguration for usage.
 	configuration *configuration
+
+	// stopBackgroundJobs is closed when plugin deactivates to stop the scheduled jobs and the posts polling Netlify
+	stopBackgroundJobs chan bool
 }
 
 // OnActivate is invoked when the plugin is activated. If an error is returned, the plugin will be terminated.
@@ -82,5 +85,20 @@ func (p *Plugin) OnActivate() error {
 
 	// TODO : Create a post in direct Bot message to how to further configure the plugin
 
+	// Start the scheduled background jobs
+	p.stopBackgroundJobs = make(chan bool)
+	p.scheduleClusterJob(CertificateWatchdogJobName, CertificateWatchdogInterval, p.checkCertificatesOfSubscribedSites)
+	p.scheduleClusterJob(UsageWatchdogJobName, UsageWatchdogInterval, p.checkUsageOfAccounts)
+
+	return nil
+}
+
+// OnDeactivate is invoked when the plugin is deactivated.
+// https://developers.mattermost.com/extend/plugins/server/reference/#Hooks.OnDeactivate
+func (p *Plugin) OnDeactivate() error {
+	if p.stopBackgroundJobs != nil {
+		close(p.stopBackgroundJobs)
+	}
+
 	return nil
 }
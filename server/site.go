@@ -0,0 +1,556 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
+)
+
+// siteNamePattern matches names Netlify accepts for a site, which become its subdomain on netlify.app
+var siteNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// gitProviders maps hosts of repositories to the name Netlify knows their provider by
+var gitProviders = map[string]string{
+	"github.com":    "github",
+	"gitlab.com":    "gitlab",
+	"bitbucket.org": "bitbucket",
+}
+
+// "/netlify site create"
+func (p *Plugin) handleSiteCreateCommand(args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		p.sendMessageFromBot(channelID, userID, true, "Error! Site URL is not defined in the App")
+		return &model.CommandResponse{}, nil
+	}
+
+	accounts, err := p.listAccountsOfUser(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get your Netlify team accounts.\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	var accountOptions []*model.PostActionOptions
+	for _, account := range accounts {
+		accountOptions = append(accountOptions, &model.PostActionOptions{
+			Text:  account.Name,
+			Value: account.Slug,
+		})
+	}
+
+	var defaultAccount string
+	if len(accounts) != 0 {
+		defaultAccount = accounts[0].Slug
+	}
+
+	siteCreateDialog := model.OpenDialogRequest{
+		TriggerId: args.TriggerId,
+		URL:       fmt.Sprintf("%s/plugins/netlify/dialog/site-create", *siteURL),
+		Dialog: model.Dialog{
+			CallbackId:       DialogSiteCreate,
+			Title:            "Create a Netlify site",
+			IntroductionText: "A deploy key is created for the repository, it is sent to you after the site is created to be added to the repository.",
+			SubmitLabel:      "Create",
+			State:            p.getConfiguration().EncryptionKey,
+			Elements: []model.DialogElement{{
+				DisplayName: "Site name",
+				Name:        "name",
+				Type:        "text",
+				Placeholder: "my-site",
+				HelpText:    "Lowercase letters, numbers and hyphens. Leave empty to let Netlify pick a random name.",
+				Optional:    true,
+				MaxLength:   63,
+			}, {
+				DisplayName: "Team account",
+				Name:        "account",
+				Type:        "select",
+				Default:     defaultAccount,
+				Options:     accountOptions,
+			}, {
+				DisplayName: "Repository URL",
+				Name:        "repository",
+				Type:        "text",
+				SubType:     "url",
+				Placeholder: "https://github.com/owner/repository",
+				HelpText:    "Repository on GitHub, GitLab or Bitbucket. Leave empty to create a site without continuous deployment.",
+				Optional:    true,
+			}, {
+				DisplayName: "Branch",
+				Name:        "branch",
+				Type:        "text",
+				Default:     "master",
+				Optional:    true,
+			}, {
+				DisplayName: "Build command",
+				Name:        "command",
+				Type:        "text",
+				Placeholder: "npm run build",
+				Optional:    true,
+			}, {
+				DisplayName: "Publish directory",
+				Name:        "directory",
+				Type:        "text",
+				Placeholder: "public",
+				Optional:    true,
+			}},
+		},
+	}
+
+	appErr := p.API.OpenInteractiveDialog(siteCreateDialog)
+	if appErr != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to open the dialog for creating a site.\n"+
+				"*Error : %v*", appErr.Error()))
+	}
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleSiteCreateDialogSubmission(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	dialogSubmission := model.SubmitDialogRequestFromJson(r.Body)
+	if dialogSubmission == nil {
+		http.Error(w, "Invalid dialog submission", http.StatusBadRequest)
+		return
+	}
+
+	// If dialog was not opened from within MM
+	if dialogSubmission.State != p.getConfiguration().EncryptionKey || dialogSubmission.UserId != authUserID {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	userID := dialogSubmission.UserId
+	channelID := dialogSubmission.ChannelId
+
+	siteName := strings.ToLower(strings.TrimSpace(getDialogSubmissionValue(dialogSubmission, "name")))
+	accountSlug := getDialogSubmissionValue(dialogSubmission, "account")
+	repositoryURL := strings.TrimSpace(getDialogSubmissionValue(dialogSubmission, "repository"))
+
+	dialogErrors := map[string]string{}
+
+	if len(siteName) != 0 && !siteNamePattern.MatchString(siteName) {
+		dialogErrors["name"] = "Site name can only have lowercase letters, numbers and hyphens, and can't start or end with a hyphen."
+	}
+
+	siteSetup := &netlifyModels.SiteSetup{
+		Site: netlifyModels.Site{
+			Name: siteName,
+		},
+	}
+
+	if len(repositoryURL) != 0 {
+		repoInfo, err := getRepoInfoFromURL(repositoryURL)
+		if err != nil {
+			dialogErrors["repository"] = err.Error()
+		} else {
+			repoInfo.RepoBranch = strings.TrimSpace(getDialogSubmissionValue(dialogSubmission, "branch"))
+			repoInfo.Cmd = strings.TrimSpace(getDialogSubmissionValue(dialogSubmission, "command"))
+			repoInfo.Dir = strings.TrimSpace(getDialogSubmissionValue(dialogSubmission, "directory"))
+			siteSetup.Repo = repoInfo
+		}
+	}
+
+	// Without Netlify app installed on the repository, Netlify can only clone it with a deploy key added to the repository
+	var deployKey *netlifyModels.DeployKey
+	if siteSetup.Repo != nil && len(dialogErrors) == 0 {
+		createdDeployKey, err := p.createDeployKey(userID)
+		if err != nil {
+			dialogErrors["repository"] = fmt.Sprintf("Failed to create a deploy key for the repository : %v", err.Error())
+		} else {
+			deployKey = createdDeployKey
+			siteSetup.Repo.DeployKeyID = deployKey.ID
+		}
+	}
+
+	if len(dialogErrors) != 0 {
+		writeSubmitDialogResponse(w, &model.SubmitDialogResponse{Errors: dialogErrors})
+		return
+	}
+
+	site, err := p.createSite(userID, accountSlug, siteSetup)
+	if err != nil {
+		// The deploy key isn't of use without the site
+		if deployKey != nil {
+			p.deleteDeployKey(userID, deployKey.ID)
+		}
+
+		writeSubmitDialogResponse(w, &model.SubmitDialogResponse{Error: fmt.Sprintf("Failed to create the site : %v", err.Error())})
+		return
+	}
+
+	err = p.recordAuditEvent(site.ID, userID, "site_create", fmt.Sprintf("%v site in %v account", site.Name, accountSlug))
+	if err != nil {
+		p.API.LogError("Failed to save audit record of site creation", "site_id", site.ID, "error", err.Error())
+	}
+
+	// Netlify leaves out the repository when it can't link it, which the channel is told about rather than a deploy that won't happen
+	var repositoryText string
+	isRepositoryLinked := site.BuildSettings != nil && len(site.BuildSettings.RepoURL) != 0
+	switch {
+	case siteSetup.Repo != nil && isRepositoryLinked:
+		repositoryText = fmt.Sprintf("\n*Repository* : %v, building `%v` branch once the deploy key is added to the repository", repositoryURL, siteSetup.Repo.RepoBranch)
+	case siteSetup.Repo != nil:
+		repositoryText = fmt.Sprintf("\n*Repository* : Netlify didn't link %v, please link it from %v/settings/deploys", repositoryURL, site.AdminURL)
+	}
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(":tada: %v created **%v** site\n"+
+		"*URL* : %v\n"+
+		"*Admin* : %v%v",
+		p.getUserMention(userID), site.Name, site.URL, site.AdminURL, repositoryText))
+
+	if deployKey != nil && isRepositoryLinked {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":key: Netlify clones %v with the deploy key below, please add it to the repository as a read-only deploy key.\n"+
+			"```\n%v\n```\n"+
+			"Builds on every push also need Netlify app installed on the repository, until then run `/netlify deploy %v` to build the site.",
+			repositoryURL, deployKey.PublicKey, site.Name))
+	}
+
+	p.sendSubscribeOfferForSite(userID, channelID, site.ID, site.Name)
+}
+
+// SiteDialogState is carried by dialogs about a site, so their submission knows which site it is for
+type SiteDialogState struct {
+	ActionSecret string `json:"action_secret"`
+	SiteID       string `json:"site_id"`
+	SiteName     string `json:"site_name"`
+}
+
+// "/netlify site delete <site>"
+func (p *Plugin) handleSiteDeleteCommand(args *model.CommandArgs, siteNameOrID string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	if p.isUserAllowedAdminCommands(userID, args.TeamId, channelID) == false {
+		p.sendMessageFromBot(channelID, userID, true, ":no_entry: Only system admins or roles configured in plugin settings can delete sites.")
+		return &model.CommandResponse{}, nil
+	}
+
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		p.sendMessageFromBot(channelID, userID, true, "Error! Site URL is not defined in the App")
+		return &model.CommandResponse{}, nil
+	}
+
+	site, err := p.resolveSiteForChange(userID, siteNameOrID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	dialogStateInBytes, err := json.Marshal(&SiteDialogState{
+		ActionSecret: p.getConfiguration().EncryptionKey,
+		SiteID:       site.ID,
+		SiteName:     site.Name,
+	})
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to open the dialog for deleting **%v** site.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	siteDeleteDialog := model.OpenDialogRequest{
+		TriggerId: args.TriggerId,
+		URL:       fmt.Sprintf("%s/plugins/netlify/dialog/site-delete", *siteURL),
+		Dialog: model.Dialog{
+			CallbackId: DialogSiteDelete,
+			Title:      fmt.Sprintf("Delete %v site", site.Name),
+			IntroductionText: fmt.Sprintf(":warning: Deleting **%v** site takes down %v along with all of its deploys, forms, functions and settings. "+
+				"This can't be undone.", site.Name, site.URL),
+			SubmitLabel: "Delete",
+			State:       string(dialogStateInBytes),
+			Elements: []model.DialogElement{{
+				DisplayName: "Site name",
+				Name:        "confirmation",
+				Type:        "text",
+				Placeholder: site.Name,
+				HelpText:    fmt.Sprintf("Type %v to confirm.", site.Name),
+			}},
+		},
+	}
+
+	appErr := p.API.OpenInteractiveDialog(siteDeleteDialog)
+	if appErr != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to open the dialog for deleting **%v** site.\n"+
+				"*Error : %v*", site.Name, appErr.Error()))
+	}
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleSiteDeleteDialogSubmission(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	dialogSubmission := model.SubmitDialogRequestFromJson(r.Body)
+	if dialogSubmission == nil {
+		http.Error(w, "Invalid dialog submission", http.StatusBadRequest)
+		return
+	}
+
+	dialogState := &SiteDialogState{}
+	err := json.Unmarshal([]byte(dialogSubmission.State), dialogState)
+
+	// If dialog was not opened from within MM
+	if err != nil || dialogState.ActionSecret != p.getConfiguration().EncryptionKey || dialogSubmission.UserId != authUserID {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	userID := dialogSubmission.UserId
+	channelID := dialogSubmission.ChannelId
+
+	// Roles could have changed while the dialog was open
+	if p.isUserAllowedAdminCommands(userID, dialogSubmission.TeamId, channelID) == false {
+		writeSubmitDialogResponse(w, &model.SubmitDialogResponse{Error: "Only system admins or roles configured in plugin settings can delete sites."})
+		return
+	}
+
+	if getDialogSubmissionValue(dialogSubmission, "confirmation") != dialogState.SiteName {
+		writeSubmitDialogResponse(w, &model.SubmitDialogResponse{Errors: map[string]string{
+			"confirmation": fmt.Sprintf("Site name doesn't match, type %v exactly to delete the site.", dialogState.SiteName),
+		}})
+		return
+	}
+
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		writeSubmitDialogResponse(w, &model.SubmitDialogResponse{Error: fmt.Sprintf("Authentication failed : %v", err.Error())})
+		return
+	}
+
+	deleteSiteParams := &netlifyPlumbingModels.DeleteSiteParams{
+		SiteID:  dialogState.SiteID,
+		Context: ctx,
+	}
+
+	_, err = netlifyClient.Operations.DeleteSite(deleteSiteParams, netlifyClientCredentials)
+	if err != nil {
+		writeSubmitDialogResponse(w, &model.SubmitDialogResponse{Error: fmt.Sprintf("Failed to delete the site : %v", err.Error())})
+		return
+	}
+
+	// Audit records of the site are kept, so the deletion can still be looked into later
+	err = p.recordAuditEvent(dialogState.SiteID, userID, "site_delete", dialogState.SiteName)
+	if err != nil {
+		p.API.LogError("Failed to save audit record of site deletion", "site_id", dialogState.SiteID, "error", err.Error())
+	}
+
+	channelsSubscribedTo, err := p.deleteSiteDataFromStore(dialogState.SiteID)
+	if err != nil {
+		p.API.LogError("Failed to clean up data of deleted site", "site_id", dialogState.SiteID, "error", err.Error())
+	}
+
+	deletedSiteMessage := fmt.Sprintf(":wastebasket: %v deleted **%v** site from Netlify.", p.getUserMention(userID), dialogState.SiteName)
+
+	p.sendMessageFromBot(channelID, "", false, deletedSiteMessage)
+
+	for _, channelSubscribedTo := range channelsSubscribedTo {
+		if channelSubscribedTo == channelID {
+			continue
+		}
+
+		p.sendMessageFromBot(channelSubscribedTo, "", false, deletedSiteMessage+" This channel won't receive its notifications anymore.")
+	}
+}
+
+// deleteSiteDataFromStore removes everything the plugin stored for a site except its audit records.
+// It returns the channels which were subscribed to the site.
+func (p *Plugin) deleteSiteDataFromStore(siteID string) ([]string, error) {
+	channelsSubscribedTo, err := p.getWebhookSubscriptionForSite(siteID)
+	if err != nil {
+		return nil, err
+	}
+
+	siteKVIdentifiers := []string{
+		NetlifyWebhookSubscriptionsKVIdentifier,
+		NetlifyDeployLockKVIdentifier,
+		NetlifyDeployTagsKVIdentifier,
+		NetlifyCertificateWatchKVIdentifier,
+	}
+
+	for _, siteKVIdentifier := range siteKVIdentifiers {
+		appErr := p.API.KVDelete(siteID + siteKVIdentifier)
+		if appErr != nil {
+			return channelsSubscribedTo, appErr
+		}
+	}
+
+	return channelsSubscribedTo, nil
+}
+
+// sendSubscribeOfferForSite lets the user subscribe the channel to notifications of the site with a single click
+func (p *Plugin) sendSubscribeOfferForSite(userID string, channelID string, siteID string, siteName string) {
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		return
+	}
+
+	// Button carries the site the same way the site dropdown of subscribe command does
+	subscribeButton := &model.PostAction{
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Name: "Subscribe this channel",
+		Integration: &model.PostActionIntegration{
+			URL: fmt.Sprintf("%s/plugins/netlify/command/subscribe", *siteURL),
+			Context: map[string]interface{}{
+				"actionSecret":    p.getConfiguration().EncryptionKey,
+				"selected_option": siteID + " " + siteName,
+			},
+		},
+	}
+
+	subscribeOfferAttachment := &model.SlackAttachment{
+		Title:   "Subscribe to Netlify notifications",
+		Text:    fmt.Sprintf("Would you like this channel to receive build start, success and fail notifications of **%v** site?", siteName),
+		Actions: []*model.PostAction{subscribeButton},
+		Footer:  "If however you don't wish to subscribe, hit the (x) cross icon on the right to dismiss this message",
+	}
+
+	p.API.SendEphemeralPost(userID, &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": []*model.SlackAttachment{subscribeOfferAttachment},
+		},
+	})
+}
+
+// createSite creates the site in the team account, or in the default account of the user if none is given
+func (p *Plugin) createSite(userID string, accountSlug string, siteSetup *netlifyModels.SiteSetup) (*netlifyModels.Site, error) {
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(accountSlug) == 0 {
+		createSiteParams := &netlifyPlumbingModels.CreateSiteParams{
+			Site:    siteSetup,
+			Context: ctx,
+		}
+
+		createSiteResponse, err := netlifyClient.Operations.CreateSite(createSiteParams, netlifyClientCredentials)
+		if err != nil {
+			return nil, err
+		}
+
+		return createSiteResponse.GetPayload(), nil
+	}
+
+	createSiteInTeamParams := &netlifyPlumbingModels.CreateSiteInTeamParams{
+		AccountSlug: accountSlug,
+		Site:        siteSetup,
+		Context:     ctx,
+	}
+
+	createSiteInTeamResponse, err := netlifyClient.Operations.CreateSiteInTeam(createSiteInTeamParams, netlifyClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return createSiteInTeamResponse.GetPayload(), nil
+}
+
+// createDeployKey creates a deploy key, with which Netlify clones a repository the key is added to
+func (p *Plugin) createDeployKey(userID string) (*netlifyModels.DeployKey, error) {
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	createDeployKeyResponse, err := netlifyClient.Operations.CreateDeployKey(netlifyPlumbingModels.NewCreateDeployKeyParamsWithContext(ctx), netlifyClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return createDeployKeyResponse.GetPayload(), nil
+}
+
+func (p *Plugin) deleteDeployKey(userID string, deployKeyID string) {
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return
+	}
+
+	deleteDeployKeyParams := &netlifyPlumbingModels.DeleteDeployKeyParams{
+		KeyID:   deployKeyID,
+		Context: ctx,
+	}
+
+	_, err = netlifyClient.Operations.DeleteDeployKey(deleteDeployKeyParams, netlifyClientCredentials)
+	if err != nil {
+		p.API.LogError("Failed to delete deploy key of site which wasn't created", "deploy_key_id", deployKeyID, "error", err.Error())
+	}
+}
+
+// getRepoInfoFromURL returns the repository settings Netlify needs for continuous deployment from a repository URL
+func getRepoInfoFromURL(repositoryURL string) (*netlifyModels.RepoInfo, error) {
+	parsedRepositoryURL, err := url.Parse(repositoryURL)
+	if err != nil || len(parsedRepositoryURL.Host) == 0 {
+		return nil, fmt.Errorf("Repository URL should be like https://github.com/owner/repository")
+	}
+
+	provider, isProviderKnown := gitProviders[strings.ToLower(parsedRepositoryURL.Host)]
+	if isProviderKnown == false {
+		return nil, fmt.Errorf("Only repositories on GitHub, GitLab or Bitbucket can be linked")
+	}
+
+	repositoryPath := strings.TrimSuffix(strings.Trim(parsedRepositoryURL.Path, "/"), ".git")
+	if strings.Count(repositoryPath, "/") < 1 {
+		return nil, fmt.Errorf("Repository URL should have both owner and repository eg. https://%v/owner/repository", parsedRepositoryURL.Host)
+	}
+
+	return &netlifyModels.RepoInfo{
+		Provider: provider,
+		RepoPath: repositoryPath,
+		RepoURL:  fmt.Sprintf("https://%v/%v", parsedRepositoryURL.Host, repositoryPath),
+	}, nil
+}
+
+func getDialogSubmissionValue(dialogSubmission *model.SubmitDialogRequest, name string) string {
+	value, _ := dialogSubmission.Submission[name].(string)
+	return value
+}
+
+// writeSubmitDialogResponse sends errors back to the dialog, which keeps it open for the user to correct them
+func writeSubmitDialogResponse(w http.ResponseWriter, submitDialogResponse *model.SubmitDialogResponse) {
+	w.Write(submitDialogResponse.ToJson())
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+)
+
+// How closely a search term matches a site, closer matches are lower
+const (
+	siteMatchExactID int = iota
+	siteMatchExact
+	siteMatchPrefix
+	siteMatchSubstring
+	siteMatchFuzzy
+)
+
+// SiteMatch is a site found for a search term, along with what of the site matched and how closely
+type SiteMatch struct {
+	Site      *netlifyModels.Site
+	MatchedOn string
+	Closeness int
+}
+
+// siteSearchField is a field of a site which search terms are matched against
+type siteSearchField struct {
+	Name    string
+	Value   string
+	IsFuzzy bool
+}
+
+func (p *Plugin) handleSearchCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	searchTerm := strings.Join(parameters, " ")
+	if len(searchTerm) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, "Please mention what to search for eg. `/netlify search <name, id or domain>`")
+		return &model.CommandResponse{}, nil
+	}
+
+	sites, err := p.listSitesOfUser(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Failed to receive sites list from Netlify : %v", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	siteMatches := searchSites(sites, searchTerm)
+	if len(siteMatches) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":mag: No Netlify site matches **%v**", searchTerm))
+		return &model.CommandResponse{}, nil
+	}
+
+	var searchResultsTitle string = fmt.Sprintf("#### :mag: %v site(s) matching %v", len(siteMatches), searchTerm)
+	if len(siteMatches) > SiteSearchResultsLimit {
+		searchResultsTitle = fmt.Sprintf("#### :mag: Closest %v of %v sites matching %v", SiteSearchResultsLimit, len(siteMatches), searchTerm)
+		siteMatches = siteMatches[:SiteSearchResultsLimit]
+	}
+
+	// Create a table with just the header, rows will fill up in the loop
+	var searchResultsMarkdownTable string = MarkdownSiteSearchTableHeader
+	for _, siteMatch := range siteMatches {
+		searchResultsMarkdownTable = fmt.Sprintf("%v\n| %v | %v | %v | %v |", searchResultsMarkdownTable,
+			siteMatch.Site.Name, siteMatch.MatchedOn, siteMatch.Site.URL, siteMatch.Site.ID)
+	}
+
+	p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("%v\n%v", searchResultsTitle, searchResultsMarkdownTable))
+
+	return &model.CommandResponse{}, nil
+}
+
+// resolveSite returns the Netlify site of the user which the name, id, domain or part of them passed in the command points to.
+// All the commands taking a site look it up with it, so they understand the site the same way.
+func (p *Plugin) resolveSite(userID string, siteSearchTerm string) (*netlifyModels.Site, error) {
+	return p.resolveSiteMatchingAtLeast(userID, siteSearchTerm, siteMatchFuzzy)
+}
+
+// resolveSiteForChange is resolveSite for the commands changing a site, which only take its id or its exact name or domain,
+// so a partly typed term never changes a site other than the one meant. Site is looked up by its id first, before listing all the sites.
+func (p *Plugin) resolveSiteForChange(userID string, siteSearchTerm string) (*netlifyModels.Site, error) {
+	siteID := strings.TrimSpace(siteSearchTerm)
+	if len(siteID) != 0 && !strings.ContainsAny(siteID, "/ ") {
+		site, err := p.getSiteByID(userID, siteID)
+		if err == nil && site.ID == siteID {
+			return site, nil
+		}
+	}
+
+	return p.resolveSiteMatchingAtLeast(userID, siteSearchTerm, siteMatchExact)
+}
+
+func (p *Plugin) resolveSiteMatchingAtLeast(userID string, siteSearchTerm string, leastCloseness int) (*netlifyModels.Site, error) {
+	sites, err := p.listSitesOfUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	allSiteMatches := searchSites(sites, siteSearchTerm)
+	if len(allSiteMatches) == 0 {
+		return nil, fmt.Errorf("No Netlify site matches %v", siteSearchTerm)
+	}
+
+	var siteMatches []*SiteMatch
+	for _, siteMatch := range allSiteMatches {
+		if siteMatch.Closeness <= leastCloseness {
+			siteMatches = append(siteMatches, siteMatch)
+		}
+	}
+
+	if len(siteMatches) == 0 {
+		return nil, fmt.Errorf("%v only partly matches %v site(s), changing a site needs its id or its exact name or domain. "+
+			"Please run `/netlify search %v` to find it", siteSearchTerm, len(allSiteMatches), siteSearchTerm)
+	}
+
+	// A single site matching exactly wins over the others matching partly
+	isOnlyExactMatch := siteMatches[0].Closeness <= siteMatchExact &&
+		(len(siteMatches) == 1 || siteMatches[1].Closeness > siteMatches[0].Closeness)
+
+	if len(siteMatches) == 1 || isOnlyExactMatch == true {
+		return siteMatches[0].Site, nil
+	}
+
+	var siteNames []string
+	for _, siteMatch := range siteMatches {
+		if len(siteNames) == SiteResolveCandidatesLimit {
+			siteNames = append(siteNames, "...")
+			break
+		}
+		siteNames = append(siteNames, siteMatch.Site.Name)
+	}
+
+	return nil, fmt.Errorf("%v matches %v sites : %v. Please be more specific or run `/netlify search %v`",
+		siteSearchTerm, len(siteMatches), strings.Join(siteNames, ", "), siteSearchTerm)
+}
+
+// searchSitesForDropdown returns the sites of the user matching the search term to be shown in a dropdown, all of them if the term is empty.
+// Mattermost doesn't show too many options, so only the closest ones are returned along with a note on narrowing them down.
+func (p *Plugin) searchSitesForDropdown(userID string, siteSearchTerm string) ([]*netlifyModels.Site, string, error) {
+	sites, err := p.listSitesOfUser(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(siteSearchTerm) != 0 {
+		var matchingSites []*netlifyModels.Site
+		for _, siteMatch := range searchSites(sites, siteSearchTerm) {
+			matchingSites = append(matchingSites, siteMatch.Site)
+		}
+		sites = matchingSites
+	}
+
+	if len(sites) <= SiteDropdownOptionsLimit {
+		return sites, "", nil
+	}
+
+	return sites[:SiteDropdownOptionsLimit], fmt.Sprintf("Only %v of %v sites are listed, add part of a site name or domain to the command to narrow them down.",
+		SiteDropdownOptionsLimit, len(sites)), nil
+}
+
+func describeNoSitesFound(siteSearchTerm string) string {
+	if len(siteSearchTerm) == 0 {
+		return ":spider_web: You don't seem to have any Netlify sites"
+	}
+
+	return fmt.Sprintf(":mag: No Netlify site matches **%v**, run `/netlify search <term>` to look for it", siteSearchTerm)
+}
+
+// searchSites returns the sites matching the search term on their name, id, custom domain, aliases or Netlify domain, closest first.
+// Besides exact, prefix and substring matches, a site also matches fuzzily when all the characters of the term appear in order.
+func searchSites(sites []*netlifyModels.Site, searchTerm string) []*SiteMatch {
+	searchTerm = normalizeSiteSearchTerm(searchTerm)
+	if len(searchTerm) == 0 {
+		return nil
+	}
+
+	var siteMatches []*SiteMatch
+	for _, site := range sites {
+		if site.ID == searchTerm {
+			siteMatches = append(siteMatches, &SiteMatch{Site: site, MatchedOn: "ID", Closeness: siteMatchExactID})
+			continue
+		}
+
+		// Fuzzy matching is left out for domains and ids, as short terms would match almost all of them
+		siteFields := []*siteSearchField{
+			{Name: "Name", Value: site.Name, IsFuzzy: true},
+			{Name: "Custom domain", Value: site.CustomDomain},
+			{Name: "Netlify domain", Value: normalizeSiteSearchTerm(site.URL)},
+		}
+		for _, domainAlias := range site.DomainAliases {
+			siteFields = append(siteFields, &siteSearchField{Name: "Domain alias", Value: domainAlias})
+		}
+
+		var closestMatch *SiteMatch
+		for _, siteField := range siteFields {
+			closeness, isMatched := matchSiteSearchTerm(strings.ToLower(siteField.Value), searchTerm, siteField.IsFuzzy)
+			if isMatched == false {
+				continue
+			}
+
+			if closestMatch == nil || closeness < closestMatch.Closeness {
+				closestMatch = &SiteMatch{Site: site, MatchedOn: siteField.Name, Closeness: closeness}
+			}
+		}
+
+		if closestMatch == nil && strings.HasPrefix(site.ID, searchTerm) {
+			closestMatch = &SiteMatch{Site: site, MatchedOn: "ID", Closeness: siteMatchPrefix}
+		}
+
+		if closestMatch != nil {
+			siteMatches = append(siteMatches, closestMatch)
+		}
+	}
+
+	// Shorter names are closer among the sites which matched alike
+	sort.SliceStable(siteMatches, func(i, j int) bool {
+		if siteMatches[i].Closeness != siteMatches[j].Closeness {
+			return siteMatches[i].Closeness < siteMatches[j].Closeness
+		}
+		if len(siteMatches[i].Site.Name) != len(siteMatches[j].Site.Name) {
+			return len(siteMatches[i].Site.Name) < len(siteMatches[j].Site.Name)
+		}
+		return siteMatches[i].Site.Name < siteMatches[j].Site.Name
+	})
+
+	return siteMatches
+}
+
+func matchSiteSearchTerm(value string, searchTerm string, isFuzzy bool) (int, bool) {
+	switch {
+	case len(value) == 0:
+		return 0, false
+	case value == searchTerm:
+		return siteMatchExact, true
+	case strings.HasPrefix(value, searchTerm):
+		return siteMatchPrefix, true
+	case strings.Contains(value, searchTerm):
+		return siteMatchSubstring, true
+	case isFuzzy == true && isSubsequence(value, searchTerm):
+		return siteMatchFuzzy, true
+	default:
+		return 0, false
+	}
+}
+
+// normalizeSiteSearchTerm lowercases the term and strips the scheme and path from it, so pasted site URLs match their domain
+func normalizeSiteSearchTerm(searchTerm string) string {
+	searchTerm = strings.ToLower(strings.TrimSpace(searchTerm))
+	searchTerm = strings.TrimPrefix(searchTerm, "https://")
+	searchTerm = strings.TrimPrefix(searchTerm, "http://")
+
+	if pathIndex := strings.Index(searchTerm, "/"); pathIndex != -1 {
+		searchTerm = searchTerm[:pathIndex]
+	}
+
+	return searchTerm
+}
+
+// isSubsequence returns true if all the characters of subsequence appear in s in the same order
+func isSubsequence(s string, subsequence string) bool {
+	subsequenceRunes := []rune(subsequence)
+
+	matchedRunes := 0
+	for _, r := range s {
+		if matchedRunes < len(subsequenceRunes) && r == subsequenceRunes[matchedRunes] {
+			matchedRunes++
+		}
+	}
+
+	return matchedRunes == len(subsequenceRunes)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"testing"
+
+	netlifyModels "github.com/netlify/open-api/go/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchSites(t *testing.T) {
+	sites := []*netlifyModels.Site{
+		{ID: "3f1a0c9e-1111", Name: "marketing-site", URL: "https://marketing-site.netlify.app", CustomDomain: "www.example.com"},
+		{ID: "8b2d4e6f-2222", Name: "blog", URL: "https://blog.netlify.app", DomainAliases: []string{"blog.example.com"}},
+		{ID: "c4e6a8b0-3333", Name: "blog-preview", URL: "https://blog-preview.netlify.app"},
+		{ID: "d5f7b9c1-4444", Name: "blog-docs", URL: "https://blog-docs.netlify.app"},
+		{ID: "e6a8c0d2-5555", Name: "docs", URL: "https://docs.netlify.app"},
+	}
+
+	for name, test := range map[string]struct {
+		SearchTerm        string
+		ExpectedNames     []string
+		ExpectedMatchedOn []string
+		ExpectedCloseness []int
+	}{
+		"exact name comes before prefixes": {
+			SearchTerm:        "blog",
+			ExpectedNames:     []string{"blog", "blog-docs", "blog-preview"},
+			ExpectedMatchedOn: []string{"Name", "Name", "Name"},
+			ExpectedCloseness: []int{siteMatchExact, siteMatchPrefix, siteMatchPrefix},
+		},
+		"ties are broken by shorter name, then by name": {
+			SearchTerm:        "docs",
+			ExpectedNames:     []string{"docs", "blog-docs"},
+			ExpectedMatchedOn: []string{"Name", "Name"},
+			ExpectedCloseness: []int{siteMatchExact, siteMatchSubstring},
+		},
+		"exact id": {
+			SearchTerm:        "8b2d4e6f-2222",
+			ExpectedNames:     []string{"blog"},
+			ExpectedMatchedOn: []string{"ID"},
+			ExpectedCloseness: []int{siteMatchExactID},
+		},
+		"id prefix": {
+			SearchTerm:        "3f1a",
+			ExpectedNames:     []string{"marketing-site"},
+			ExpectedMatchedOn: []string{"ID"},
+			ExpectedCloseness: []int{siteMatchPrefix},
+		},
+		"custom domain pasted as URL": {
+			SearchTerm:        "https://www.example.com/pricing",
+			ExpectedNames:     []string{"marketing-site"},
+			ExpectedMatchedOn: []string{"Custom domain"},
+			ExpectedCloseness: []int{siteMatchExact},
+		},
+		"domain alias": {
+			SearchTerm:        "BLOG.example.com",
+			ExpectedNames:     []string{"blog"},
+			ExpectedMatchedOn: []string{"Domain alias"},
+			ExpectedCloseness: []int{siteMatchExact},
+		},
+		"Netlify domain with scheme, also inside a longer domain": {
+			SearchTerm:        "http://docs.netlify.app/",
+			ExpectedNames:     []string{"docs", "blog-docs"},
+			ExpectedMatchedOn: []string{"Netlify domain", "Netlify domain"},
+			ExpectedCloseness: []int{siteMatchExact, siteMatchSubstring},
+		},
+		"fuzzy on name only": {
+			SearchTerm:        "mkt",
+			ExpectedNames:     []string{"marketing-site"},
+			ExpectedMatchedOn: []string{"Name"},
+			ExpectedCloseness: []int{siteMatchFuzzy},
+		},
+		"no match": {
+			SearchTerm: "shop",
+		},
+		"empty term": {
+			SearchTerm: "  ",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var names, matchedOn []string
+			var closeness []int
+			for _, siteMatch := range searchSites(sites, test.SearchTerm) {
+				names = append(names, siteMatch.Site.Name)
+				matchedOn = append(matchedOn, siteMatch.MatchedOn)
+				closeness = append(closeness, siteMatch.Closeness)
+			}
+
+			assert.Equal(t, test.ExpectedNames, names)
+			assert.Equal(t, test.ExpectedMatchedOn, matchedOn)
+			assert.Equal(t, test.ExpectedCloseness, closeness)
+		})
+	}
+}
+
+func TestMatchSiteSearchTerm(t *testing.T) {
+	for name, test := range map[string]struct {
+		Value             string
+		SearchTerm        string
+		IsFuzzy           bool
+		ExpectedCloseness int
+		ExpectedIsMatched bool
+	}{
+		"exact":                   {Value: "blog", SearchTerm: "blog", ExpectedCloseness: siteMatchExact, ExpectedIsMatched: true},
+		"prefix":                  {Value: "blog-docs", SearchTerm: "blog", ExpectedCloseness: siteMatchPrefix, ExpectedIsMatched: true},
+		"substring":               {Value: "my-blog", SearchTerm: "blog", ExpectedCloseness: siteMatchSubstring, ExpectedIsMatched: true},
+		"fuzzy":                   {Value: "marketing-site", SearchTerm: "mkt", IsFuzzy: true, ExpectedCloseness: siteMatchFuzzy, ExpectedIsMatched: true},
+		"fuzzy when not fuzzy":    {Value: "marketing-site", SearchTerm: "mkt"},
+		"characters out of order": {Value: "marketing-site", SearchTerm: "tkm", IsFuzzy: true},
+		"empty value":             {Value: "", SearchTerm: "blog", IsFuzzy: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			closeness, isMatched := matchSiteSearchTerm(test.Value, test.SearchTerm, test.IsFuzzy)
+			assert.Equal(t, test.ExpectedIsMatched, isMatched)
+			assert.Equal(t, test.ExpectedCloseness, closeness)
+		})
+	}
+}
+
+func TestNormalizeSiteSearchTerm(t *testing.T) {
+	for name, test := range map[string]struct {
+		SearchTerm string
+		Expected   string
+	}{
+		"plain name":           {SearchTerm: "blog", Expected: "blog"},
+		"uppercase and spaces": {SearchTerm: "  Blog ", Expected: "blog"},
+		"https URL":            {SearchTerm: "https://blog.netlify.app", Expected: "blog.netlify.app"},
+		"http URL with path":   {SearchTerm: "http://www.example.com/about/team", Expected: "www.example.com"},
+		"domain with slash":    {SearchTerm: "www.example.com/", Expected: "www.example.com"},
+		"uppercase scheme":     {SearchTerm: "HTTPS://WWW.Example.com", Expected: "www.example.com"},
+		"only scheme":          {SearchTerm: "https://", Expected: ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, normalizeSiteSearchTerm(test.SearchTerm))
+		})
+	}
+}
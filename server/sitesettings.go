@@ -0,0 +1,316 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+)
+
+// SiteSetting is a processing or build setting of a site which can be edited from the settings dialog
+type SiteSetting struct {
+	Name        string
+	DisplayName string
+	Value       string
+	IsToggle    bool
+}
+
+// siteSettingToggleOptions are the choices of a toggle in the settings dialog, as select is supported by all Mattermost versions
+var siteSettingToggleOptions = []*model.PostActionOptions{
+	{Text: "On", Value: "true"},
+	{Text: "Off", Value: "false"},
+}
+
+func (p *Plugin) handleSiteSettingsEditResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	siteID, _ := intergrationResponseFromCommand.Context["siteID"].(string)
+	siteName, _ := intergrationResponseFromCommand.Context["siteName"].(string)
+
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		p.sendMessageFromBot(channelID, userID, true, "Error! Site URL is not defined in the App")
+		return
+	}
+
+	// Anyone in the channel can press the button, so settings are read with their own account
+	accessToken, err := p.getNetlifyUserAccessTokenFromStore(userID)
+	if err != nil || len(accessToken) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, "You must connect your Netlify account first.\nPlease run `/netlify connect`")
+		return
+	}
+
+	site, err := p.getSiteByID(userID, siteID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get **%v** site.\n"+
+				"*Error : %v*", siteName, err.Error()))
+		return
+	}
+
+	dialogStateInBytes, err := json.Marshal(&SiteDialogState{
+		ActionSecret: actionSecret,
+		SiteID:       site.ID,
+		SiteName:     site.Name,
+	})
+	if err != nil {
+		return
+	}
+
+	var dialogElements []model.DialogElement
+	for _, siteSetting := range getEditableSiteSettings(site) {
+		dialogElement := model.DialogElement{
+			DisplayName: siteSetting.DisplayName,
+			Name:        siteSetting.Name,
+			Type:        "text",
+			Default:     siteSetting.Value,
+			Optional:    true,
+		}
+
+		if siteSetting.IsToggle == true {
+			dialogElement.Type = "select"
+			dialogElement.Options = siteSettingToggleOptions
+			dialogElement.Optional = false
+		}
+
+		dialogElements = append(dialogElements, dialogElement)
+	}
+
+	siteSettingsDialog := model.OpenDialogRequest{
+		TriggerId: intergrationResponseFromCommand.TriggerId,
+		URL:       fmt.Sprintf("%s/plugins/netlify/dialog/site-settings", *siteURL),
+		Dialog: model.Dialog{
+			CallbackId:       DialogSiteSettings,
+			Title:            fmt.Sprintf("Settings of %v site", site.Name),
+			IntroductionText: "Asset optimization settings apply to the next deploy, build settings to the next build.",
+			SubmitLabel:      "Save",
+			State:            string(dialogStateInBytes),
+			Elements:         dialogElements,
+		},
+	}
+
+	appErr := p.API.OpenInteractiveDialog(siteSettingsDialog)
+	if appErr != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to open the dialog for editing settings of **%v** site.\n"+
+				"*Error : %v*", site.Name, appErr.Error()))
+	}
+}
+
+func (p *Plugin) handleSiteSettingsDialogSubmission(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	dialogSubmission := model.SubmitDialogRequestFromJson(r.Body)
+	if dialogSubmission == nil {
+		http.Error(w, "Invalid dialog submission", http.StatusBadRequest)
+		return
+	}
+
+	dialogState := &SiteDialogState{}
+	err := json.Unmarshal([]byte(dialogSubmission.State), dialogState)
+
+	// If dialog was not opened from within MM
+	if err != nil || dialogState.ActionSecret != p.getConfiguration().EncryptionKey || dialogSubmission.UserId != authUserID {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	userID := dialogSubmission.UserId
+	channelID := dialogSubmission.ChannelId
+
+	// Settings are compared with the site as it is now, in case it was changed while the dialog was open
+	site, err := p.getSiteByID(userID, dialogState.SiteID)
+	if err != nil {
+		writeSubmitDialogResponse(w, &model.SubmitDialogResponse{Error: fmt.Sprintf("Failed to get the site : %v", err.Error())})
+		return
+	}
+
+	var settingsDiffMarkdownTable string = MarkdownSiteSettingsDiffTableHeader
+	var changedSettingNames []string
+
+	newSettingValues := map[string]string{}
+	for _, siteSetting := range getEditableSiteSettings(site) {
+		newValue := strings.TrimSpace(getDialogSubmissionValue(dialogSubmission, siteSetting.Name))
+		newSettingValues[siteSetting.Name] = newValue
+
+		if newValue == siteSetting.Value {
+			continue
+		}
+
+		changedSettingNames = append(changedSettingNames, siteSetting.Name)
+		settingsDiffMarkdownTable = fmt.Sprintf("%v\n| %v | %v | %v |", settingsDiffMarkdownTable, siteSetting.DisplayName,
+			describeSiteSettingValue(siteSetting, siteSetting.Value), describeSiteSettingValue(siteSetting, newValue))
+	}
+
+	if len(changedSettingNames) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":ok_hand: Nothing was changed in settings of **%v** site.", site.Name))
+		return
+	}
+
+	siteUpdate, err := getSiteSettingsUpdate(site, newSettingValues)
+	if err != nil {
+		writeSubmitDialogResponse(w, &model.SubmitDialogResponse{Error: fmt.Sprintf("Failed to update the site : %v", err.Error())})
+		return
+	}
+
+	// Netlify library leaves out settings which are turned off, so the update is sent as is to the same endpoint UpdateSite uses
+	err = p.sendNetlifyAPIRequest(userID, http.MethodPatch, fmt.Sprintf("/sites/%v", site.ID), nil, siteUpdate, nil)
+	if err != nil {
+		writeSubmitDialogResponse(w, &model.SubmitDialogResponse{Error: fmt.Sprintf("Failed to update the site : %v", err.Error())})
+		return
+	}
+
+	err = p.recordAuditEvent(site.ID, userID, "site_settings", strings.Join(changedSettingNames, ", "))
+	if err != nil {
+		p.API.LogError("Failed to save audit record of site settings change", "site_id", site.ID, "error", err.Error())
+	}
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(":gear: %v changed settings of **%v** site\n%v",
+		p.getUserMention(userID), site.Name, settingsDiffMarkdownTable))
+}
+
+// getEditableSiteSettings returns the processing and build settings of the site which the settings dialog shows
+func getEditableSiteSettings(site *netlifyModels.Site) []*SiteSetting {
+	processingSettings := &netlifyModels.SiteProcessingSettings{}
+	if site.ProcessingSettings != nil {
+		processingSettings = site.ProcessingSettings
+	}
+
+	css := &netlifyModels.MinifyOptions{}
+	if processingSettings.CSS != nil {
+		css = processingSettings.CSS
+	}
+
+	js := &netlifyModels.MinifyOptions{}
+	if processingSettings.Js != nil {
+		js = processingSettings.Js
+	}
+
+	var prettyURLs, optimizeImages bool
+	if processingSettings.HTML != nil {
+		prettyURLs = processingSettings.HTML.PrettyUrls
+	}
+	if processingSettings.Images != nil {
+		optimizeImages = processingSettings.Images.Optimize
+	}
+
+	toggle := func(name string, displayName string, value bool) *SiteSetting {
+		return &SiteSetting{Name: name, DisplayName: displayName, Value: strconv.FormatBool(value), IsToggle: true}
+	}
+
+	siteSettings := []*SiteSetting{
+		toggle("asset_optimization", "Asset optimization", !processingSettings.Skip),
+		toggle("css_bundle", "Bundle CSS", css.Bundle),
+		toggle("css_minify", "Minify CSS", css.Minify),
+		toggle("js_bundle", "Bundle JS", js.Bundle),
+		toggle("js_minify", "Minify JS", js.Minify),
+		toggle("pretty_urls", "Pretty URLs", prettyURLs),
+		toggle("images_optimize", "Optimize images", optimizeImages),
+	}
+
+	// Build settings only matter for sites built from a repository
+	if site.BuildSettings != nil && len(site.BuildSettings.RepoURL) != 0 {
+		siteSettings = append(siteSettings,
+			&SiteSetting{Name: "repo_branch", DisplayName: "Production branch", Value: site.BuildSettings.RepoBranch},
+			&SiteSetting{Name: "cmd", DisplayName: "Build command", Value: site.BuildSettings.Cmd},
+			&SiteSetting{Name: "dir", DisplayName: "Publish directory", Value: site.BuildSettings.Dir},
+		)
+	}
+
+	return siteSettings
+}
+
+// getSiteSettingsUpdate returns the body for updating the site with the settings from the dialog
+func getSiteSettingsUpdate(site *netlifyModels.Site, settingValues map[string]string) (map[string]interface{}, error) {
+	isOn := func(name string) bool {
+		return settingValues[name] == "true"
+	}
+
+	siteUpdate := map[string]interface{}{
+		"processing_settings": map[string]interface{}{
+			"skip": !isOn("asset_optimization"),
+			"css": map[string]bool{
+				"bundle": isOn("css_bundle"),
+				"minify": isOn("css_minify"),
+			},
+			"js": map[string]bool{
+				"bundle": isOn("js_bundle"),
+				"minify": isOn("js_minify"),
+			},
+			"html": map[string]bool{
+				"pretty_urls": isOn("pretty_urls"),
+			},
+			"images": map[string]bool{
+				"optimize": isOn("images_optimize"),
+			},
+		},
+	}
+
+	if _, hasBuildSettings := settingValues["repo_branch"]; hasBuildSettings == false {
+		return siteUpdate, nil
+	}
+
+	// The rest of build settings like environment variables are sent along unchanged
+	buildSettingsInBytes, err := json.Marshal(site.BuildSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	buildSettings := map[string]interface{}{}
+	err = json.Unmarshal(buildSettingsInBytes, &buildSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	buildSettings["repo_branch"] = settingValues["repo_branch"]
+	buildSettings["cmd"] = settingValues["cmd"]
+	buildSettings["dir"] = settingValues["dir"]
+
+	siteUpdate["build_settings"] = buildSettings
+
+	return siteUpdate, nil
+}
+
+func describeSiteSettingValue(siteSetting *SiteSetting, value string) string {
+	switch {
+	case siteSetting.IsToggle == true && value == "true":
+		return ":white_check_mark: On"
+	case siteSetting.IsToggle == true:
+		return ":negative_squared_cross_mark: Off"
+	case len(value) == 0:
+		return "*None*"
+	default:
+		return fmt.Sprintf("`%v`", value)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
+)
+
+func (p *Plugin) handleSSLCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	if len(parameters) != 1 {
+		p.sendMessageFromBot(channelID, userID, true, "Please mention the site eg. `/netlify ssl <site>`")
+		return &model.CommandResponse{}, nil
+	}
+
+	site, err := p.resolveSiteForChange(userID, parameters[0])
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	sslPost, err := p.getSiteSSLPost(userID, channelID, site.ID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get SSL information of **%v** site.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	p.API.SendEphemeralPost(userID, sslPost)
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleSSLCommandResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+	originalPostID := intergrationResponseFromCommand.PostId
+
+	actionSecretPassed, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if actionSecret != actionSecretPassed {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	actionToBeTaken, _ := intergrationResponseFromCommand.Context["action"].(string)
+	siteID, _ := intergrationResponseFromCommand.Context["siteID"].(string)
+	siteName, _ := intergrationResponseFromCommand.Context["siteName"].(string)
+
+	var actionMessage, auditAction string
+
+	switch actionToBeTaken {
+	case ActionProvisionCertificate:
+		// Get the netlify client
+		netlifyClient, ctx := p.getNetlifyClient()
+		netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+			return
+		}
+
+		// Without a certificate of our own, Netlify provisions one from Let's Encrypt
+		provisionSiteTLSCertificateParams := &netlifyPlumbingModels.ProvisionSiteTLSCertificateParams{
+			SiteID:  siteID,
+			Context: ctx,
+		}
+
+		_, err = netlifyClient.Operations.ProvisionSiteTLSCertificate(provisionSiteTLSCertificateParams, netlifyClientCredentials)
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Failed to provision certificate of **%v** site.\n"+
+					"*Error : %v*", siteName, err.Error()))
+			return
+		}
+
+		auditAction = "ssl_provision"
+		actionMessage = fmt.Sprintf(":closed_lock_with_key: %v asked Netlify to provision a certificate for **%v** site.", p.getUserMention(userID), siteName)
+	case ActionForceHTTPS:
+		site, err := p.getSiteByID(userID, siteID)
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Failed to get **%v** site.\n"+
+					"*Error : %v*", siteName, err.Error()))
+			return
+		}
+
+		_, err = p.updateSite(userID, site, &netlifyModels.Site{ForceSsl: true})
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Failed to force HTTPS on **%v** site.\n"+
+					"*Error : %v*", siteName, err.Error()))
+			return
+		}
+
+		auditAction = "ssl_force_https"
+		actionMessage = fmt.Sprintf(":closed_lock_with_key: %v forced HTTPS on **%v** site, HTTP requests are now redirected to HTTPS.", p.getUserMention(userID), siteName)
+	default:
+		return
+	}
+
+	err := p.recordAuditEvent(siteID, userID, auditAction, "")
+	if err != nil {
+		p.API.LogError("Failed to save audit record of SSL change", "site_id", siteID, "error", err.Error())
+	}
+
+	p.sendMessageFromBot(channelID, "", false, actionMessage)
+
+	// Refresh the SSL post so it reflects the change
+	sslPost, err := p.getSiteSSLPost(userID, channelID, siteID)
+	if err != nil {
+		return
+	}
+
+	sslPost.Id = originalPostID
+	p.API.UpdateEphemeralPost(userID, sslPost)
+}
+
+// getSiteSSLPost returns a post with the certificate of the site, along with the buttons to provision a certificate and to force HTTPS
+func (p *Plugin) getSiteSSLPost(userID string, channelID string, siteID string) (*model.Post, error) {
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		return nil, fmt.Errorf("Site URL is not defined in the App")
+	}
+
+	site, err := p.getSiteByID(userID, siteID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sslText string
+	certificate, err := p.getSiteTLSCertificate(userID, site.ID)
+	if err != nil || certificate == nil || len(certificate.State) == 0 {
+		sslText = "*No certificate is provisioned yet. Netlify provisions one once DNS of the domains points to Netlify.*\n"
+	} else {
+		sslText = fmt.Sprintf("*State* : %v\n"+
+			"*Domains* : %v\n"+
+			"*Created at* : %v\n"+
+			"*Updated at* : %v\n"+
+			"*Expires at* : %v\n",
+			certificate.State, strings.Join(certificate.Domains, ", "), formatNetlifyDate(certificate.CreatedAt),
+			formatNetlifyDate(certificate.UpdatedAt), formatNetlifyDate(certificate.ExpiresAt))
+	}
+
+	var forceSSL string = "No"
+	if site.ForceSsl == true {
+		forceSSL = "Yes"
+	}
+	sslText = sslText + fmt.Sprintf("*HTTPS forced* : %v", forceSSL)
+
+	buttonContext := func(action string) map[string]interface{} {
+		return map[string]interface{}{
+			"action":       action,
+			"actionSecret": p.getConfiguration().EncryptionKey,
+			"siteID":       site.ID,
+			"siteName":     site.Name,
+		}
+	}
+
+	sslActions := []*model.PostAction{{
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Name: "Provision certificate",
+		Integration: &model.PostActionIntegration{
+			URL:     fmt.Sprintf("%s/plugins/netlify/command/ssl", *siteURL),
+			Context: buttonContext(ActionProvisionCertificate),
+		},
+	}}
+
+	// HTTPS can only be forced once there is a certificate
+	if site.ForceSsl == false && certificate != nil && len(certificate.State) != 0 {
+		sslActions = append(sslActions, &model.PostAction{
+			Type: model.POST_ACTION_TYPE_BUTTON,
+			Name: "Force HTTPS",
+			Integration: &model.PostActionIntegration{
+				URL:     fmt.Sprintf("%s/plugins/netlify/command/ssl", *siteURL),
+				Context: buttonContext(ActionForceHTTPS),
+			},
+		})
+	}
+
+	sslAttachment := &model.SlackAttachment{
+		Title:   fmt.Sprintf("SSL certificate of %v site", site.Name),
+		Text:    sslText,
+		Actions: sslActions,
+	}
+
+	return &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": []*model.SlackAttachment{sslAttachment},
+		},
+	}, nil
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
+)
+
+func (p *Plugin) handleStatusCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+	actionSecret := p.getConfiguration().EncryptionKey
+
+	// "/netlify status <site>" shows the status straight away
+	if len(parameters) != 0 {
+		site, err := p.resolveSite(userID, strings.Join(parameters, " "))
+		if err != nil {
+			p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+				":exclamation: Failed to find the site\n"+
+					"*Error : %v*", err.Error()))
+			return &model.CommandResponse{}, nil
+		}
+
+		p.postSiteStatus(userID, channelID, site.ID, site.Name)
+		return &model.CommandResponse{}, nil
+	}
+
+	// Check if SiteURL is defined in the app
+	siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+	if siteURL == nil {
+		p.sendMessageFromBot(channelID, userID, true, "Error! Site URL is not defined in the App")
+		return &model.CommandResponse{}, nil
+	}
+
+	sites, sitesLeftOutNote, err := p.searchSitesForDropdown(userID, "")
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Failed to receive sites list from Netlify : %v", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// If user has no netlify sites
+	if len(sites) == 0 {
+		p.sendMessageFromBot(channelID, userID, true, describeNoSitesFound(""))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Create an empty array of options we will be using for dropdown
+	var sitesDropdownOptions []*model.PostActionOptions
+
+	// Loop over all the sites
+	for _, site := range sites {
+		siteOption := &model.PostActionOptions{
+			Text:  fmt.Sprintf("%v", site.Name),
+			Value: fmt.Sprintf("%v %v", site.ID, site.Name),
+		}
+		// Store name, id information of all the sites inside the dropdown option
+		sitesDropdownOptions = append(sitesDropdownOptions, siteOption)
+	}
+
+	// Construct a dropdown
+	sitesDropdown := &model.PostAction{
+		Type:     model.POST_ACTION_TYPE_SELECT,
+		Name:     "Select a site",
+		Disabled: false,
+		Options:  sitesDropdownOptions,
+		Integration: &model.PostActionIntegration{
+			URL: fmt.Sprintf("%s/plugins/netlify/command/status", *siteURL),
+			Context: map[string]interface{}{
+				"actionSecret": actionSecret,
+			},
+		},
+	}
+
+	statusCommandAttachment := &model.SlackAttachment{
+		Pretext: "View deploy status of Netlify site",
+		Title:   "Select a site you want to view the deploy status of.",
+		Actions: []*model.PostAction{sitesDropdown},
+		Footer:  sitesLeftOutNote,
+	}
+
+	statusCommandPost := &model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Props: map[string]interface{}{
+			"attachments": []*model.SlackAttachment{statusCommandAttachment},
+		},
+	}
+
+	// Present the user with the site dropdown
+	p.API.SendEphemeralPost(userID, statusCommandPost)
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) handleStatusCommandResponse(w http.ResponseWriter, r *http.Request) {
+	// Check if this was passed within Mattermost
+	authUserID := r.Header.Get("Mattermost-User-ID")
+	if authUserID == "" {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse the JSON
+	intergrationResponseFromCommand := model.PostActionIntegrationRequestFromJson(r.Body)
+
+	userID := intergrationResponseFromCommand.UserId
+	channelID := intergrationResponseFromCommand.ChannelId
+	originalPostID := intergrationResponseFromCommand.PostId
+
+	receivedActionSecret, _ := intergrationResponseFromCommand.Context["actionSecret"].(string)
+	storedActionSecret := p.getConfiguration().EncryptionKey
+
+	// If action was not initiated from within MM
+	if storedActionSecret != receivedActionSecret {
+		http.Error(w, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Bifurcate the received value to get id and name of site
+	selectedOption, _ := intergrationResponseFromCommand.Context["selected_option"].(string)
+	selectedOptionsValue := strings.Fields(selectedOption)
+
+	// Check if any is empty
+	if len(selectedOptionsValue) != 2 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: One of more values while selecting from dropdown were empty"))
+		http.Error(w, "One of more values while selecting from dropdown were empty", http.StatusNotAcceptable)
+		return
+	}
+
+	siteID := selectedOptionsValue[0]
+	siteName := selectedOptionsValue[1]
+
+	// Update the message of original dropdown message post
+	p.API.UpdateEphemeralPost(userID, &model.Post{
+		Id:        originalPostID,
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Message:   fmt.Sprintf(":hourglass: Fetching deploy status of **%v** site.", siteName),
+	})
+
+	p.postSiteStatus(userID, channelID, siteID, siteName)
+}
+
+// postSiteStatus posts the deploy status of the site in the channel.
+// If any deploy is in progress, the post keeps refreshing itself in background until all deploys finish.
+func (p *Plugin) postSiteStatus(userID string, channelID string, siteID string, siteName string) {
+	statusMessage, isAnyDeployInProgress, err := p.getSiteStatusMessage(userID, siteID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get **%v** site deploy status.\n"+
+				"*Error : %v*", siteName, err.Error()))
+		return
+	}
+
+	statusPost, appErr := p.API.CreatePost(&model.Post{
+		UserId:    p.BotUserID,
+		ChannelId: channelID,
+		Message:   statusMessage,
+	})
+	if appErr != nil || isAnyDeployInProgress == false {
+		return
+	}
+
+	go p.refreshSiteStatusPost(userID, statusPost, siteID)
+}
+
+// refreshSiteStatusPost updates the status post every few seconds until no deploy is in progress or refreshing times out
+func (p *Plugin) refreshSiteStatusPost(userID string, statusPost *model.Post, siteID string) {
+	deadline := time.Now().Add(SiteStatusRefreshTimeout)
+	for time.Now().Before(deadline) {
+		// Stop refreshing if the plugin is deactivated meanwhile
+		select {
+		case <-time.After(SiteStatusRefreshInterval):
+		case <-p.stopBackgroundJobs:
+			return
+		}
+
+		statusMessage, isAnyDeployInProgress, err := p.getSiteStatusMessage(userID, siteID)
+		if err != nil {
+			// Try again on next refresh
+			continue
+		}
+
+		statusPost.Message = statusMessage
+		updatedStatusPost, appErr := p.API.UpdatePost(statusPost)
+		if appErr != nil {
+			// Post was probably deleted, no point in refreshing further
+			return
+		}
+		statusPost = updatedStatusPost
+
+		if isAnyDeployInProgress == false {
+			return
+		}
+	}
+}
+
+// getSiteStatusMessage returns the published deploy along with building, enqueued and failed deploys of the site in markdown.
+// It also tells if any of the deploys is still in progress.
+func (p *Plugin) getSiteStatusMessage(userID string, siteID string) (string, bool, error) {
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return "", false, err
+	}
+
+	getSiteParams := &netlifyPlumbingModels.GetSiteParams{
+		SiteID:  siteID,
+		Context: ctx,
+	}
+
+	// Published deploy changes once a deploy finishes, hence site is fetched on every refresh
+	getSiteResponse, err := netlifyClient.Operations.GetSite(getSiteParams, netlifyClientCredentials)
+	if err != nil {
+		return "", false, err
+	}
+
+	site := getSiteResponse.GetPayload()
+
+	deploys, err := p.listSiteDeploys(userID, site.ID, 1, SiteStatusDeploysPerPage, nil)
+	if err != nil {
+		return "", false, err
+	}
+
+	statusMessage := fmt.Sprintf("#### :traffic_light: Deploy status of %v\n", site.Name)
+
+	// Failed deploys older than the published one are not of interest anymore
+	var publishedDeployCreatedAt time.Time
+	if site.PublishedDeploy != nil && len(site.PublishedDeploy.ID) != 0 {
+		publishedDeploy := site.PublishedDeploy
+		publishedDeployCreatedAt, _ = time.Parse(NetlifyDateLayout, publishedDeploy.CreatedAt)
+
+		statusMessage = statusMessage + fmt.Sprintf("##### Published deploy\n"+
+			"*Deploy ID* : %v\n"+
+			"*Branch* : %v\n"+
+			"*Commit* : %v\n"+
+			"*Published at* : %v\n"+
+			"*URL* : %v\n",
+			publishedDeploy.ID, publishedDeploy.Branch, describeDeployCommit(publishedDeploy.Title, publishedDeploy.CommitRef),
+			formatNetlifyDate(publishedDeploy.PublishedAt), site.URL)
+	} else {
+		statusMessage = statusMessage + "*This site has no published deploy yet*\n"
+	}
+
+	var isAnyDeployInProgress bool = false
+	var deployMarkdownTable string = MarkdownDeployStatusTableHeader
+	var deployTableRows int = 0
+
+	for _, deploy := range deploys {
+		isInProgress := isDeployInProgress(deploy.State)
+		if isInProgress == false && deploy.State != NetlifyEventStateDeployFailed {
+			continue
+		}
+
+		deployStartedAt, err := time.Parse(NetlifyDateLayout, deploy.CreatedAt)
+		if err != nil {
+			continue
+		}
+
+		if isInProgress == false && deployStartedAt.Before(publishedDeployCreatedAt) {
+			continue
+		}
+
+		// Running deploys are timed till now, finished ones till they were last updated
+		var deployElapsed time.Duration
+		if isInProgress == true {
+			isAnyDeployInProgress = true
+			deployElapsed = time.Since(deployStartedAt)
+		} else if deployUpdatedAt, err := time.Parse(NetlifyDateLayout, deploy.UpdatedAt); err == nil {
+			deployElapsed = deployUpdatedAt.Sub(deployStartedAt)
+		}
+
+		var deployTableRow string = fmt.Sprintf("| %v %v | %v | %v | %v | %v | %v |",
+			getDeployStateIcon(deploy.State), deploy.State, deploy.Branch, describeDeployCommit(deploy.Title, deploy.CommitRef),
+			deployStartedAt.Format(time.RFC822), deployElapsed.Round(time.Second), deploy.ID)
+		deployMarkdownTable = fmt.Sprintf("%v\n%v", deployMarkdownTable, deployTableRow)
+		deployTableRows = deployTableRows + 1
+	}
+
+	if deployTableRows == 0 {
+		statusMessage = statusMessage + "\n*No deploys are building, enqueued or failed since the published deploy*"
+	} else {
+		statusMessage = statusMessage + "\n##### Other deploys" + deployMarkdownTable
+	}
+
+	if isAnyDeployInProgress == true {
+		statusMessage = statusMessage + fmt.Sprintf("\n\n*Refreshing every %v until the deploys finish, last refreshed at %v*",
+			SiteStatusRefreshInterval, time.Now().Format(time.RFC822))
+	}
+
+	return statusMessage, isAnyDeployInProgress, nil
+}
+
+// isDeployInProgress tells if a deploy is yet to reach a terminal state
+func isDeployInProgress(deployState string) bool {
+	switch deployState {
+	case NetlifyDeployStateNew, NetlifyDeployStateEnqueued, NetlifyEventStateDeployBuilding,
+		NetlifyDeployStateUploading, NetlifyDeployStateUploaded, NetlifyDeployStateProcessing:
+		return true
+	}
+
+	return false
+}
+
+func getDeployStateIcon(deployState string) string {
+	if deployState == NetlifyEventStateDeployFailed {
+		return ":red_circle:"
+	}
+
+	if isDeployInProgress(deployState) == true {
+		return ":white_circle:"
+	}
+
+	return ":large_blue_circle:"
+}
+
+// describeDeployCommit returns first line of the commit message along with short SHA, as the deploys command shows them
+func describeDeployCommit(title string, commitRef string) string {
+	if len(commitRef) == 0 {
+		return describeDeployTitle(title)
+	}
+
+	return fmt.Sprintf("%v (%v)", describeDeployTitle(title), describeDeploySHA(commitRef))
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
+)
+
+// DeployTag is a label given to a deploy from Mattermost eg. known-good
+type DeployTag struct {
+	Tag             string `json:"tag"`
+	Note            string `json:"note"`
+	UserID          string `json:"user_id"`
+	TaggedAt        int64  `json:"tagged_at"`
+	DeployCreatedAt string `json:"deploy_created_at"`
+}
+
+func (p *Plugin) handleDeployTagCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	// Eg. /netlify deploy tag <site> <deployID> known-good "passed QA"
+	arguments, _ := parseCommandFlags(parameters)
+	if len(arguments) < 3 {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			"Please mention the site, deploy and the tag eg. `/netlify deploy tag <site> <deployID> %v \"passed QA\"`", DeployTagKnownGood))
+		return &model.CommandResponse{}, nil
+	}
+
+	siteNameOrID := arguments[0]
+	deployID := arguments[1]
+	tag := arguments[2]
+	note := strings.Join(arguments[3:], " ")
+
+	site, err := p.resolveSiteForChange(userID, siteNameOrID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the site\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Get the Netlify library client for interacting with netlify api
+	netlifyClient, ctx := p.getNetlifyClient()
+
+	// Get Netlify credentials
+	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Make sure the deploy belongs to the site before tagging it
+	getSiteDeployParams := &netlifyPlumbingModels.GetSiteDeployParams{
+		SiteID:   site.ID,
+		DeployID: deployID,
+		Context:  ctx,
+	}
+
+	getSiteDeployResponse, err := netlifyClient.Operations.GetSiteDeploy(getSiteDeployParams, netlifyCredentials)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find %v deploy of **%v** site.\n"+
+				"*Error : %v*", deployID, site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	deploy := getSiteDeployResponse.GetPayload()
+
+	deployTags, err := p.getDeployTagsForSite(site.ID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get tags of **%v** site.\n"+
+				"*Error : %v*", site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Tagging again with the same tag replaces the earlier note
+	var tagsOfDeploy []*DeployTag
+	for _, existingTag := range deployTags[deploy.ID] {
+		if existingTag.Tag != tag {
+			tagsOfDeploy = append(tagsOfDeploy, existingTag)
+		}
+	}
+
+	tagsOfDeploy = append(tagsOfDeploy, &DeployTag{
+		Tag:             tag,
+		Note:            note,
+		UserID:          userID,
+		TaggedAt:        model.GetMillis(),
+		DeployCreatedAt: deploy.CreatedAt,
+	})
+	deployTags[deploy.ID] = tagsOfDeploy
+
+	err = p.setDeployTagsForSite(site.ID, deployTags)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to tag %v deploy of **%v** site.\n"+
+				"*Error : %v*", deploy.ID, site.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	tagMessage := fmt.Sprintf(":label: %v tagged %v deploy of **%v** site as `%v`", p.getUserMention(userID), deploy.ID, site.Name, tag)
+	if len(note) != 0 {
+		tagMessage = fmt.Sprintf("%v : %v", tagMessage, note)
+	}
+
+	p.sendMessageFromBot(channelID, "", false, tagMessage)
+
+	return &model.CommandResponse{}, nil
+}
+
+// getLastKnownGoodDeployID returns the most recently created deploy of the site which is tagged known-good, empty if there is none
+func (p *Plugin) getLastKnownGoodDeployID(siteID string) (string, error) {
+	deployTags, err := p.getDeployTagsForSite(siteID)
+	if err != nil {
+		return "", err
+	}
+
+	var lastKnownGoodDeployID string
+	var lastKnownGoodDeployCreatedAt time.Time
+
+	for deployID, tagsOfDeploy := range deployTags {
+		for _, deployTag := range tagsOfDeploy {
+			if deployTag.Tag != DeployTagKnownGood {
+				continue
+			}
+
+			deployCreatedAt, err := time.Parse(NetlifyDateLayout, deployTag.DeployCreatedAt)
+			if err != nil {
+				continue
+			}
+
+			if len(lastKnownGoodDeployID) == 0 || deployCreatedAt.After(lastKnownGoodDeployCreatedAt) {
+				lastKnownGoodDeployID = deployID
+				lastKnownGoodDeployCreatedAt = deployCreatedAt
+			}
+		}
+	}
+
+	return lastKnownGoodDeployID, nil
+}
+
+// describeDeployTags returns tags of a deploy in a form which can be put in a markdown table cell
+func describeDeployTags(tagsOfDeploy []*DeployTag) string {
+	if len(tagsOfDeploy) == 0 {
+		return "-"
+	}
+
+	var tags []string
+	for _, deployTag := range tagsOfDeploy {
+		if len(deployTag.Note) != 0 {
+			tags = append(tags, fmt.Sprintf("`%v` %v", deployTag.Tag, deployTag.Note))
+		} else {
+			tags = append(tags, fmt.Sprintf("`%v`", deployTag.Tag))
+		}
+	}
+
+	return strings.Join(tags, ", ")
+}
+
+func (p *Plugin) setDeployTagsForSite(siteID string, deployTags map[string][]*DeployTag) error {
+	// Unique identifier
+	deployTagsIdentifier := siteID + NetlifyDeployTagsKVIdentifier
+
+	deployTagsInBytes, err := json.Marshal(deployTags)
+	if err != nil {
+		return err
+	}
+
+	appErr := p.API.KVSet(deployTagsIdentifier, deployTagsInBytes)
+	if appErr != nil {
+		return appErr
+	}
+
+	return nil
+}
+
+// getDeployTagsForSite returns tags of all the tagged deploys of a site, mapped by deploy id
+func (p *Plugin) getDeployTagsForSite(siteID string) (map[string][]*DeployTag, error) {
+	deployTagsIdentifier := siteID + NetlifyDeployTagsKVIdentifier
+
+	deployTags := map[string][]*DeployTag{}
+
+	deployTagsInBytes, appErr := p.API.KVGet(deployTagsIdentifier)
+	if appErr != nil {
+		return deployTags, appErr
+	}
+
+	// It returns nil if value is not found
+	if deployTagsInBytes == nil {
+		return deployTags, nil
+	}
+
+	err := json.Unmarshal(deployTagsInBytes, &deployTags)
+	if err != nil {
+		return deployTags, err
+	}
+
+	return deployTags, nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
+)
+
+// teamMemberRoles are the roles Netlify lets members of a team account have
+var teamMemberRoles = []string{"Owner", "Collaborator", "Controller"}
+
+func (p *Plugin) handleTeamCommand(args *model.CommandArgs, parameters []string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	arguments, flags := parseCommandFlags(parameters)
+
+	subcommand := ""
+	if len(arguments) != 0 {
+		subcommand = arguments[0]
+	}
+
+	// Account is optional and comes after the subcommand, or after the email for invite and remove
+	accountNameOrSlug := func(index int) string {
+		if len(arguments) <= index {
+			return ""
+		}
+		return arguments[index]
+	}
+
+	switch {
+	// "/netlify team members [account]"
+	case subcommand == "members" && len(arguments) <= 2:
+		p.sendTeamMembersPost(userID, channelID, accountNameOrSlug(1))
+	// "/netlify team invite <email> [account] [--role Collaborator]"
+	case subcommand == "invite" && (len(arguments) == 2 || len(arguments) == 3):
+		if p.isUserAllowedAdminCommands(userID, args.TeamId, channelID) == false {
+			p.sendMessageFromBot(channelID, userID, true, ":no_entry: Only system admins or roles configured in plugin settings can manage team members.")
+			return &model.CommandResponse{}, nil
+		}
+
+		p.inviteTeamMember(userID, channelID, arguments[1], accountNameOrSlug(2), flags["role"])
+	// "/netlify team remove <email> [account]"
+	case subcommand == "remove" && (len(arguments) == 2 || len(arguments) == 3):
+		if p.isUserAllowedAdminCommands(userID, args.TeamId, channelID) == false {
+			p.sendMessageFromBot(channelID, userID, true, ":no_entry: Only system admins or roles configured in plugin settings can manage team members.")
+			return &model.CommandResponse{}, nil
+		}
+
+		p.removeTeamMember(userID, channelID, arguments[1], accountNameOrSlug(2))
+	default:
+		p.sendMessageFromBot(channelID, userID, true, "Please use one of the below team commands\n"+
+			"* `/netlify team members [account]`\n"+
+			"* `/netlify team invite <email> [account] [--role Owner|Collaborator|Controller]`\n"+
+			"* `/netlify team remove <email> [account]`")
+	}
+
+	return &model.CommandResponse{}, nil
+}
+
+func (p *Plugin) sendTeamMembersPost(userID string, channelID string, accountNameOrSlug string) {
+	account, err := p.getAccountFromCommandArgument(userID, accountNameOrSlug)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the team account\n"+
+				"*Error : %v*", err.Error()))
+		return
+	}
+
+	members, err := p.listTeamMembers(userID, account.Slug)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get members of **%v** team.\n"+
+				"*Error : %v*", account.Name, err.Error()))
+		return
+	}
+
+	// Owners come first
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Role != members[j].Role {
+			return members[i].Role > members[j].Role
+		}
+		return members[i].Email < members[j].Email
+	})
+
+	// Create a table with just the header, rows will fill up in the loop
+	var membersMarkdownTable string = MarkdownTeamMemberTableHeader
+	for _, member := range members {
+		membersMarkdownTable = fmt.Sprintf("%v\n| %v | %v | %v | %v |", membersMarkdownTable,
+			escapeMarkdownTableCell(member.FullName), member.Email, member.Role, member.ID)
+	}
+
+	p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("#### :busts_in_silhouette: Members of %v team\n%v", account.Name, membersMarkdownTable))
+}
+
+func (p *Plugin) inviteTeamMember(userID string, channelID string, email string, accountNameOrSlug string, role string) {
+	// Netlify makes invited members collaborators unless told otherwise
+	if len(role) == 0 {
+		role = "Collaborator"
+	}
+
+	var isRoleKnown bool = false
+	for _, teamMemberRole := range teamMemberRoles {
+		if strings.EqualFold(teamMemberRole, role) {
+			role = teamMemberRole
+			isRoleKnown = true
+		}
+	}
+
+	if isRoleKnown == false {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Role should be one of %v", strings.Join(teamMemberRoles, ", ")))
+		return
+	}
+
+	if !strings.Contains(email, "@") {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("%v doesn't look like an email address", email))
+		return
+	}
+
+	account, err := p.getAccountFromCommandArgument(userID, accountNameOrSlug)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the team account\n"+
+				"*Error : %v*", err.Error()))
+		return
+	}
+
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("Authentication failed : %v", err.Error()))
+		return
+	}
+
+	addMemberToAccountParams := &netlifyPlumbingModels.AddMemberToAccountParams{
+		AccountSlug: account.Slug,
+		Email:       email,
+		Role:        &role,
+		Context:     ctx,
+	}
+
+	_, err = netlifyClient.Operations.AddMemberToAccount(addMemberToAccountParams, netlifyClientCredentials)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to invite %v to **%v** team.\n"+
+				"*Error : %v*", email, account.Name, err.Error()))
+		return
+	}
+
+	err = p.recordTeamAuditEvent(account.ID, userID, "team_invite", fmt.Sprintf("%v as %v", email, role))
+	if err != nil {
+		p.API.LogError("Failed to save audit record of team change", "account_id", account.ID, "error", err.Error())
+	}
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(":envelope_with_arrow: %v invited %v to **%v** team as %v.",
+		p.getUserMention(userID), email, account.Name, role))
+}
+
+func (p *Plugin) removeTeamMember(userID string, channelID string, emailOrMemberID string, accountNameOrSlug string) {
+	account, err := p.getAccountFromCommandArgument(userID, accountNameOrSlug)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the team account\n"+
+				"*Error : %v*", err.Error()))
+		return
+	}
+
+	members, err := p.listTeamMembers(userID, account.Slug)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get members of **%v** team.\n"+
+				"*Error : %v*", account.Name, err.Error()))
+		return
+	}
+
+	var memberToRemove *netlifyModels.Member
+	for _, member := range members {
+		if member.ID == emailOrMemberID || strings.EqualFold(member.Email, emailOrMemberID) {
+			memberToRemove = member
+			break
+		}
+	}
+
+	if memberToRemove == nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(":exclamation: %v is not a member of **%v** team.", emailOrMemberID, account.Name))
+		return
+	}
+
+	// Netlify library doesn't have the operation for removing a member yet
+	err = p.sendNetlifyAPIRequest(userID, http.MethodDelete, fmt.Sprintf("/%v/members/%v", account.Slug, memberToRemove.ID), nil, nil, nil)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to remove %v from **%v** team.\n"+
+				"*Error : %v*", memberToRemove.Email, account.Name, err.Error()))
+		return
+	}
+
+	err = p.recordTeamAuditEvent(account.ID, userID, "team_remove", memberToRemove.Email)
+	if err != nil {
+		p.API.LogError("Failed to save audit record of team change", "account_id", account.ID, "error", err.Error())
+	}
+
+	p.sendMessageFromBot(channelID, "", false, fmt.Sprintf(":wave: %v removed %v from **%v** team.",
+		p.getUserMention(userID), memberToRemove.Email, account.Name))
+}
+
+func (p *Plugin) listTeamMembers(userID string, accountSlug string) ([]*netlifyModels.Member, error) {
+	// Get the netlify client
+	netlifyClient, ctx := p.getNetlifyClient()
+	netlifyClientCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	listMembersForAccountParams := &netlifyPlumbingModels.ListMembersForAccountParams{
+		AccountSlug: accountSlug,
+		Context:     ctx,
+	}
+
+	listMembersForAccountResponse, err := netlifyClient.Operations.ListMembersForAccount(listMembersForAccountParams, netlifyClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return listMembersForAccountResponse.GetPayload(), nil
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
+)
+
+// NetlifyUsageNumber is a usage amount in Netlify API responses, which sends some of them as strings eg. included build minutes
+type NetlifyUsageNumber int64
+
+// UnmarshalJSON reads the amount whether it is sent as a number or as a string
+func (n *NetlifyUsageNumber) UnmarshalJSON(data []byte) error {
+	amount := strings.Trim(string(data), `"`)
+	if len(amount) == 0 || amount == "null" {
+		*n = 0
+		return nil
+	}
+
+	amountParsed, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return fmt.Errorf("%v is not a usage amount", string(data))
+	}
+
+	*n = NetlifyUsageNumber(amountParsed)
+	return nil
+}
+
+// NetlifyAccountBandwidth is bandwidth used by a team account in its billing period as returned by Netlify API, which netlify library doesn't have
+type NetlifyAccountBandwidth struct {
+	Used            NetlifyUsageNumber `json:"used"`
+	Included        NetlifyUsageNumber `json:"included"`
+	PeriodStartDate string             `json:"period_start_date"`
+	PeriodEndDate   string             `json:"period_end_date"`
+	LastUpdatedAt   string             `json:"last_updated_at"`
+}
+
+// NetlifyAccountBuildStatus is state of builds of a team account as returned by Netlify API, which netlify library doesn't have
+type NetlifyAccountBuildStatus struct {
+	Minutes struct {
+		Current                  NetlifyUsageNumber `json:"current"`
+		IncludedMinutes          NetlifyUsageNumber `json:"included_minutes"`
+		IncludedMinutesWithPacks NetlifyUsageNumber `json:"included_minutes_with_packs"`
+		PeriodStartDate          string             `json:"period_start_date"`
+		PeriodEndDate            string             `json:"period_end_date"`
+		LastUpdatedAt            string             `json:"last_updated_at"`
+	} `json:"minutes"`
+}
+
+// AccountUsageLimit is usage of a team account against one limit of its plan
+type AccountUsageLimit struct {
+	Name            string
+	Used            int64
+	Included        int64
+	PeriodStartDate string
+	PeriodEndDate   string
+}
+
+// UsageAlerts remembers the thresholds already alerted for each limit of an account in a billing period, so they are alerted only once
+type UsageAlerts struct {
+	AlertedPercents map[string][]int `json:"alerted_percents"`
+}
+
+func (p *Plugin) handleUsageCommand(args *model.CommandArgs, accountNameOrSlug string) (*model.CommandResponse, *model.AppError) {
+	userID := args.UserId
+	channelID := args.ChannelId
+
+	account, err := p.getAccountFromCommandArgument(userID, accountNameOrSlug)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to find the team account\n"+
+				"*Error : %v*", err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	usageLimits, err := p.getAccountUsageLimits(userID, account)
+	if err != nil {
+		p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf(
+			":exclamation: Failed to get usage of **%v** team.\n"+
+				"*Error : %v*", account.Name, err.Error()))
+		return &model.CommandResponse{}, nil
+	}
+
+	// Create a table with just the header, rows will fill up in the loop
+	var usageMarkdownTable string = MarkdownAccountUsageTableHeader
+	for _, usageLimit := range usageLimits {
+		usageMarkdownTable = fmt.Sprintf("%v\n| %v | %v | %v | %v | %v - %v |", usageMarkdownTable,
+			usageLimit.Name, describeUsageAmount(usageLimit.Name, usageLimit.Used), describeUsageAmount(usageLimit.Name, usageLimit.Included),
+			describeUsagePercent(usageLimit), formatNetlifyDate(usageLimit.PeriodStartDate), formatNetlifyDate(usageLimit.PeriodEndDate))
+	}
+
+	p.sendMessageFromBot(channelID, userID, true, fmt.Sprintf("#### :bar_chart: Usage of %v team on %v plan\n%v",
+		account.Name, account.TypeName, usageMarkdownTable))
+
+	return &model.CommandResponse{}, nil
+}
+
+// getAccountUsageLimits returns usage of bandwidth and build minutes of the account in its current billing period
+func (p *Plugin) getAccountUsageLimits(userID string, account *netlifyModels.AccountMembership) ([]*AccountUsageLimit, error) {
+	// Netlify library doesn't have the operations for usage of an account
+	bandwidth := &NetlifyAccountBandwidth{}
+	err := p.sendNetlifyAPIRequest(userID, http.MethodGet, fmt.Sprintf("/accounts/%v/bandwidth", account.ID), nil, nil, bandwidth)
+	if err != nil {
+		return nil, err
+	}
+
+	buildStatus := &NetlifyAccountBuildStatus{}
+	err = p.sendNetlifyAPIRequest(userID, http.MethodGet, fmt.Sprintf("/%v/builds/status", account.Slug), nil, nil, buildStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	// Purchased build minute packs add to the minutes included in the plan
+	includedBuildMinutes := buildStatus.Minutes.IncludedMinutesWithPacks
+	if includedBuildMinutes == 0 {
+		includedBuildMinutes = buildStatus.Minutes.IncludedMinutes
+	}
+
+	return []*AccountUsageLimit{
+		{
+			Name:            AccountUsageLimitBandwidth,
+			Used:            int64(bandwidth.Used),
+			Included:        int64(bandwidth.Included),
+			PeriodStartDate: bandwidth.PeriodStartDate,
+			PeriodEndDate:   bandwidth.PeriodEndDate,
+		},
+		{
+			Name:            AccountUsageLimitBuildMinutes,
+			Used:            int64(buildStatus.Minutes.Current),
+			Included:        int64(includedBuildMinutes),
+			PeriodStartDate: buildStatus.Minutes.PeriodStartDate,
+			PeriodEndDate:   buildStatus.Minutes.PeriodEndDate,
+		},
+	}, nil
+}
+
+// checkUsageOfAccounts alerts the configured channel when usage of any team account crosses a threshold of its plan limits
+func (p *Plugin) checkUsageOfAccounts() {
+	usageAlertChannelID := p.getConfiguration().UsageAlertChannelID
+	if len(usageAlertChannelID) == 0 {
+		return
+	}
+
+	_, connectedUserIDs, err := p.getSubscribedSitesAndConnectedUsers()
+	if err != nil {
+		p.API.LogError("Failed to get connected users for checking usage", "error", err.Error())
+		return
+	}
+
+	// Accounts are looked up with any connected user who is a member of them
+	checkedAccountIDs := map[string]bool{}
+	for _, userID := range connectedUserIDs {
+		accounts, err := p.listAccountsOfUser(userID)
+		if err != nil {
+			p.API.LogWarn("Failed to list accounts of user for checking usage", "user_id", userID, "error", err.Error())
+			continue
+		}
+
+		for _, account := range accounts {
+			if checkedAccountIDs[account.ID] == true {
+				continue
+			}
+
+			// Another member of the account may still be able to get its usage
+			usageLimits, err := p.getAccountUsageLimits(userID, account)
+			if err != nil {
+				p.API.LogWarn("Failed to get usage of account", "account_id", account.ID, "user_id", userID, "error", err.Error())
+				continue
+			}
+
+			checkedAccountIDs[account.ID] = true
+			p.checkUsageOfAccount(usageAlertChannelID, account, usageLimits)
+		}
+	}
+}
+
+func (p *Plugin) checkUsageOfAccount(channelID string, account *netlifyModels.AccountMembership, usageLimits []*AccountUsageLimit) {
+	usageAlerts, err := p.getUsageAlertsForAccount(account.ID)
+	if err != nil {
+		p.API.LogError("Failed to get usage alerts of account", "account_id", account.ID, "error", err.Error())
+		return
+	}
+
+	var alerts []string
+	for _, usageLimit := range usageLimits {
+		if usageLimit.Included <= 0 {
+			continue
+		}
+
+		// A new billing period is alerted about afresh
+		alertIdentifier := usageLimit.Name + " " + usageLimit.PeriodStartDate
+		for alertedIdentifier := range usageAlerts.AlertedPercents {
+			if strings.HasPrefix(alertedIdentifier, usageLimit.Name+" ") && alertedIdentifier != alertIdentifier {
+				delete(usageAlerts.AlertedPercents, alertedIdentifier)
+			}
+		}
+
+		usedPercent := int(math.Floor(float64(usageLimit.Used) * 100 / float64(usageLimit.Included)))
+
+		// A single alert covers all the thresholds crossed since the last check
+		var crossedThreshold int
+		for _, threshold := range AccountUsageAlertThresholds {
+			if usedPercent < threshold || containsInt(usageAlerts.AlertedPercents[alertIdentifier], threshold) {
+				continue
+			}
+
+			usageAlerts.AlertedPercents[alertIdentifier] = append(usageAlerts.AlertedPercents[alertIdentifier], threshold)
+			crossedThreshold = threshold
+		}
+
+		if crossedThreshold == 0 {
+			continue
+		}
+
+		emoji := ":warning:"
+		if crossedThreshold >= 100 {
+			emoji = ":rotating_light:"
+		}
+
+		alerts = append(alerts, fmt.Sprintf("%v **%v** team has used %v%% of its %v, %v of %v included till %v.",
+			emoji, account.Name, usedPercent, strings.ToLower(usageLimit.Name), describeUsageAmount(usageLimit.Name, usageLimit.Used),
+			describeUsageAmount(usageLimit.Name, usageLimit.Included), formatNetlifyDate(usageLimit.PeriodEndDate)))
+	}
+
+	err = p.setUsageAlertsForAccount(account.ID, usageAlerts)
+	if err != nil {
+		p.API.LogError("Failed to save usage alerts of account", "account_id", account.ID, "error", err.Error())
+		return
+	}
+
+	if len(alerts) == 0 {
+		return
+	}
+
+	p.sendMessageFromBot(channelID, "", false, strings.Join(alerts, "\n")+
+		fmt.Sprintf("\nRun `/netlify usage %v` to see the usage.", account.Slug))
+}
+
+func describeUsageAmount(usageLimitName string, amount int64) string {
+	if usageLimitName == AccountUsageLimitBuildMinutes {
+		return fmt.Sprintf("%v min", amount)
+	}
+
+	return describeSize(amount)
+}
+
+func describeUsagePercent(usageLimit *AccountUsageLimit) string {
+	if usageLimit.Included <= 0 {
+		return "-"
+	}
+
+	usedPercent := float64(usageLimit.Used) * 100 / float64(usageLimit.Included)
+	if usedPercent >= 100 {
+		return fmt.Sprintf(":rotating_light: %.0f%%", usedPercent)
+	}
+	if usedPercent >= float64(AccountUsageAlertThresholds[0]) {
+		return fmt.Sprintf(":warning: %.0f%%", usedPercent)
+	}
+
+	return fmt.Sprintf("%.0f%%", usedPercent)
+}
+
+func (p *Plugin) setUsageAlertsForAccount(accountID string, usageAlerts *UsageAlerts) error {
+	usageAlertsInBytes, err := json.Marshal(usageAlerts)
+	if err != nil {
+		return err
+	}
+
+	appErr := p.API.KVSet(accountID+NetlifyUsageAlertsKVIdentifier, usageAlertsInBytes)
+	if appErr != nil {
+		return appErr
+	}
+
+	return nil
+}
+
+// getUsageAlertsForAccount returns the thresholds alerted for the account, empty if none were alerted
+func (p *Plugin) getUsageAlertsForAccount(accountID string) (*UsageAlerts, error) {
+	usageAlerts := &UsageAlerts{}
+
+	usageAlertsInBytes, appErr := p.API.KVGet(accountID + NetlifyUsageAlertsKVIdentifier)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// It returns nil if value is not found
+	if usageAlertsInBytes != nil {
+		err := json.Unmarshal(usageAlertsInBytes, usageAlerts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if usageAlerts.AlertedPercents == nil {
+		usageAlerts.AlertedPercents = map[string][]int{}
+	}
+
+	return usageAlerts, nil
+}
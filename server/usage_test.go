@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetlifyAccountBandwidthDecoding(t *testing.T) {
+	for name, test := range map[string]struct {
+		Payload  string
+		Expected NetlifyAccountBandwidth
+	}{
+		"bandwidth of a billing period": {
+			Payload: `{
+				"used": 16106127360,
+				"included": 107374182400,
+				"period_start_date": "2020-05-01T00:00:00.000Z",
+				"period_end_date": "2020-05-31T23:59:59.999Z",
+				"last_updated_at": "2020-05-18T10:21:47.000Z"
+			}`,
+			Expected: NetlifyAccountBandwidth{
+				Used:            16106127360,
+				Included:        107374182400,
+				PeriodStartDate: "2020-05-01T00:00:00.000Z",
+				PeriodEndDate:   "2020-05-31T23:59:59.999Z",
+				LastUpdatedAt:   "2020-05-18T10:21:47.000Z",
+			},
+		},
+		"amounts sent as strings": {
+			Payload: `{"used": "1073741824", "included": "107374182400"}`,
+			Expected: NetlifyAccountBandwidth{
+				Used:     1073741824,
+				Included: 107374182400,
+			},
+		},
+		"no bandwidth used yet": {
+			Payload: `{"used": 0, "included": 107374182400}`,
+			Expected: NetlifyAccountBandwidth{
+				Included: 107374182400,
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			bandwidth := NetlifyAccountBandwidth{}
+			require.NoError(t, json.Unmarshal([]byte(test.Payload), &bandwidth))
+			assert.Equal(t, test.Expected, bandwidth)
+		})
+	}
+}
+
+func TestNetlifyAccountBuildStatusDecoding(t *testing.T) {
+	for name, test := range map[string]struct {
+		Payload                          string
+		ExpectedCurrent                  NetlifyUsageNumber
+		ExpectedIncludedMinutes          NetlifyUsageNumber
+		ExpectedIncludedMinutesWithPacks NetlifyUsageNumber
+		ExpectedPeriodStartDate          string
+	}{
+		"build minutes with purchased packs": {
+			Payload: `{
+				"active": 1,
+				"pending_concurrency": 0,
+				"enqueued": 0,
+				"build_count": 42,
+				"minutes": {
+					"current": 250,
+					"current_average_sec": 95,
+					"previous": 310,
+					"period_start_date": "2020-05-01T00:00:00.000Z",
+					"period_end_date": "2020-05-31T23:59:59.999Z",
+					"last_updated_at": "2020-05-18T10:21:47.000Z",
+					"included_minutes": "300",
+					"included_minutes_with_packs": "800"
+				}
+			}`,
+			ExpectedCurrent:                  250,
+			ExpectedIncludedMinutes:          300,
+			ExpectedIncludedMinutesWithPacks: 800,
+			ExpectedPeriodStartDate:          "2020-05-01T00:00:00.000Z",
+		},
+		"build minutes without packs": {
+			Payload: `{
+				"minutes": {
+					"current": 12,
+					"period_start_date": "2020-05-01T00:00:00.000Z",
+					"included_minutes": 300
+				}
+			}`,
+			ExpectedCurrent:         12,
+			ExpectedIncludedMinutes: 300,
+			ExpectedPeriodStartDate: "2020-05-01T00:00:00.000Z",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			buildStatus := NetlifyAccountBuildStatus{}
+			require.NoError(t, json.Unmarshal([]byte(test.Payload), &buildStatus))
+			assert.Equal(t, test.ExpectedCurrent, buildStatus.Minutes.Current)
+			assert.Equal(t, test.ExpectedIncludedMinutes, buildStatus.Minutes.IncludedMinutes)
+			assert.Equal(t, test.ExpectedIncludedMinutesWithPacks, buildStatus.Minutes.IncludedMinutesWithPacks)
+			assert.Equal(t, test.ExpectedPeriodStartDate, buildStatus.Minutes.PeriodStartDate)
+		})
+	}
+}
+
+func TestDescribeUsagePercent(t *testing.T) {
+	for name, test := range map[string]struct {
+		UsageLimit *AccountUsageLimit
+		Expected   string
+	}{
+		"below the first threshold": {
+			UsageLimit: &AccountUsageLimit{Used: 50, Included: 300},
+			Expected:   "17%",
+		},
+		"past the first threshold": {
+			UsageLimit: &AccountUsageLimit{Used: 240, Included: 300},
+			Expected:   ":warning: 80%",
+		},
+		"over the limit": {
+			UsageLimit: &AccountUsageLimit{Used: 330, Included: 300},
+			Expected:   ":rotating_light: 110%",
+		},
+		"nothing included": {
+			UsageLimit: &AccountUsageLimit{Used: 10},
+			Expected:   "-",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, describeUsagePercent(test.UsageLimit))
+		})
+	}
+}
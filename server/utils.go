@@ -1,17 +1,25 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-openapi/runtime"
 	openapiClient "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
 	"github.com/mattermost/mattermost-server/v5/model"
+	netlifyModels "github.com/netlify/open-api/go/models"
 	"github.com/netlify/open-api/go/plumbing"
+	netlifyPlumbingModels "github.com/netlify/open-api/go/plumbing/operations"
 	"golang.org/x/oauth2"
 )
 
@@ -144,6 +152,356 @@ func (p *Plugin) getNetlifyClient() (*plumbing.Netlify, context.Context) {
 	return client, ctx
 }
 
+// sendNetlifyAPIRequest makes a request directly to Netlify API on behalf of the user.
+// It is used for the operations which are not available in netlify library client.
+// If result is not nil then the json response is decoded into it.
+func (p *Plugin) sendNetlifyAPIRequest(userID string, method string, path string, query url.Values, body interface{}, result interface{}) error {
+	// Get access token from KV store
+	accessToken, err := p.getNetlifyUserAccessTokenFromStore(userID)
+	if err != nil {
+		return err
+	}
+
+	if len(accessToken) == 0 {
+		return errors.New("Netlify account is not connected")
+	}
+
+	requestURL := url.URL{
+		Scheme: "https",
+		Host:   NetlifyAPIHost,
+		Path:   NetlifyAPIPath + path,
+	}
+
+	if query != nil {
+		requestURL.RawQuery = query.Encode()
+	}
+
+	requestBody := bytes.NewBuffer([]byte{})
+	if body != nil {
+		bodyInBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewBuffer(bodyInBytes)
+	}
+
+	request, err := http.NewRequest(method, requestURL.String(), requestBody)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+
+	response, err := p.getHTTPClient().Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("Netlify API responded with %v", response.Status)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// getSiteByID returns the Netlify site with all of its settings.
+func (p *Plugin) getSiteByID(userID string, siteID string) (*netlifyModels.Site, error) {
+	// Get the Netlify library client for interacting with netlify api
+	netlifyClient, ctx := p.getNetlifyClient()
+
+	// Get Netlify credentials
+	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	getSiteParams := &netlifyPlumbingModels.GetSiteParams{
+		SiteID:  siteID,
+		Context: ctx,
+	}
+
+	getSiteResponse, err := netlifyClient.Operations.GetSite(getSiteParams, netlifyCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return getSiteResponse.GetPayload(), nil
+}
+
+// listAccountsOfUser returns all the Netlify accounts the user is a member of, including the personal one
+func (p *Plugin) listAccountsOfUser(userID string) ([]*netlifyModels.AccountMembership, error) {
+	// Get the Netlify library client for interacting with netlify api
+	netlifyClient, ctx := p.getNetlifyClient()
+
+	// Get Netlify credentials
+	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	listAccountsForUserParams := &netlifyPlumbingModels.ListAccountsForUserParams{
+		Context: ctx,
+	}
+
+	listAccountsForUserResponse, err := netlifyClient.Operations.ListAccountsForUser(listAccountsForUserParams, netlifyCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return listAccountsForUserResponse.GetPayload(), nil
+}
+
+// getAccountFromCommandArgument returns the account of the user whose slug, name or id is same as passed in the command.
+// The first account of the user is returned when none is passed.
+func (p *Plugin) getAccountFromCommandArgument(userID string, accountNameOrSlug string) (*netlifyModels.AccountMembership, error) {
+	accounts, err := p.listAccountsOfUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("You are not a member of any Netlify account")
+	}
+
+	if len(accountNameOrSlug) == 0 {
+		return accounts[0], nil
+	}
+
+	for _, account := range accounts {
+		if account.Slug == accountNameOrSlug || account.ID == accountNameOrSlug || strings.EqualFold(account.Name, accountNameOrSlug) {
+			return account, nil
+		}
+	}
+
+	return nil, fmt.Errorf("No Netlify account found by the name or slug %v", accountNameOrSlug)
+}
+
+// updateSite updates the Netlify site with the fields set in siteUpdate and returns the updated site.
+// Netlify library always sends domain aliases, so existing aliases of the site are carried over unless siteUpdate changes them.
+func (p *Plugin) updateSite(userID string, site *netlifyModels.Site, siteUpdate *netlifyModels.Site) (*netlifyModels.Site, error) {
+	// Get the Netlify library client for interacting with netlify api
+	netlifyClient, ctx := p.getNetlifyClient()
+
+	// Get Netlify credentials
+	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if siteUpdate.DomainAliases == nil {
+		siteUpdate.DomainAliases = site.DomainAliases
+	}
+
+	updateSiteParams := &netlifyPlumbingModels.UpdateSiteParams{
+		SiteID: site.ID,
+		Site: &netlifyModels.SiteSetup{
+			Site: *siteUpdate,
+		},
+		Context: ctx,
+	}
+
+	updateSiteResponse, err := netlifyClient.Operations.UpdateSite(updateSiteParams, netlifyCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateSiteResponse.GetPayload(), nil
+}
+
+// getSiteTLSCertificate returns the certificate Netlify has provisioned for the site
+func (p *Plugin) getSiteTLSCertificate(userID string, siteID string) (*netlifyModels.SniCertificate, error) {
+	// Get the Netlify library client for interacting with netlify api
+	netlifyClient, ctx := p.getNetlifyClient()
+
+	// Get Netlify credentials
+	netlifyCredentials, err := p.getNetlifyClientCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	showSiteTLSCertificateParams := &netlifyPlumbingModels.ShowSiteTLSCertificateParams{
+		SiteID:  siteID,
+		Context: ctx,
+	}
+
+	showSiteTLSCertificateResponse, err := netlifyClient.Operations.ShowSiteTLSCertificate(showSiteTLSCertificateParams, netlifyCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	return showSiteTLSCertificateResponse.GetPayload(), nil
+}
+
+// NetlifyDeploy is a deploy as returned by Netlify API, along with the fields which netlify library model doesn't have
+type NetlifyDeploy struct {
+	netlifyModels.Deploy
+	Committer          string                   `json:"committer"`
+	DeployTime         int64                    `json:"deploy_time"`
+	AvailableFunctions []*NetlifyDeployFunction `json:"available_functions"`
+}
+
+// listSiteDeploys returns a single page of deploys of a site. Query can be used to filter the deploys at Netlify.
+// Netlify library client doesn't support pagination of deploys, hence calling the api directly.
+func (p *Plugin) listSiteDeploys(userID string, siteID string, page int, perPage int, query url.Values) ([]*NetlifyDeploy, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	var deploys []*NetlifyDeploy
+	err := p.sendNetlifyAPIRequest(userID, http.MethodGet, fmt.Sprintf("/sites/%v/deploys", siteID), query, nil, &deploys)
+	if err != nil {
+		return nil, err
+	}
+
+	return deploys, nil
+}
+
+// parseCommandFlags separates positional parameters of a command from its "--flag value" or "--flag=value" pairs.
+// Quoted values split on white space are joined back. Boolean flags of the command never take the word after them
+// and are set as "true", as is any other flag without a value.
+func parseCommandFlags(parameters []string, booleanFlags ...string) ([]string, map[string]string) {
+	var joinedParameters []string
+	for i := 0; i < len(parameters); i++ {
+		parameter := parameters[i]
+		if strings.HasPrefix(parameter, "\"") {
+			for !(len(parameter) > 1 && strings.HasSuffix(parameter, "\"")) && i+1 < len(parameters) {
+				i++
+				parameter = parameter + " " + parameters[i]
+			}
+			parameter = strings.Trim(parameter, "\"")
+		}
+		joinedParameters = append(joinedParameters, parameter)
+	}
+
+	var arguments []string
+	flags := map[string]string{}
+
+	for i := 0; i < len(joinedParameters); i++ {
+		parameter := joinedParameters[i]
+		if !strings.HasPrefix(parameter, "--") {
+			arguments = append(arguments, parameter)
+			continue
+		}
+
+		flagName := strings.TrimPrefix(parameter, "--")
+		if equalsIndex := strings.Index(flagName, "="); equalsIndex != -1 {
+			flags[flagName[:equalsIndex]] = flagName[equalsIndex+1:]
+			continue
+		}
+
+		isBooleanFlag := false
+		for _, booleanFlag := range booleanFlags {
+			if flagName == booleanFlag {
+				isBooleanFlag = true
+			}
+		}
+
+		if isBooleanFlag == false && i+1 < len(joinedParameters) && !strings.HasPrefix(joinedParameters[i+1], "--") {
+			flags[flagName] = joinedParameters[i+1]
+			i++
+		} else {
+			flags[flagName] = "true"
+		}
+	}
+
+	return arguments, flags
+}
+
+// parseDateFlag converts value of date flags like --since to time.
+// Value can be relative to now like 7d or 12h, or a date like 2020-01-31.
+func parseDateFlag(value string) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err == nil {
+			return time.Now().AddDate(0, 0, -days), nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err == nil {
+		return time.Now().Add(-duration), nil
+	}
+
+	date, err := time.Parse(CommandFlagDateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v is neither a duration like 7d nor a date like 2020-01-31", value)
+	}
+
+	return date, nil
+}
+
+// formatNetlifyDate converts the date returned by Netlify api into readable format, returns "-" if it can't.
+func formatNetlifyDate(date string) string {
+	dateParsed, err := time.Parse(NetlifyDateLayout, date)
+	if err != nil {
+		// Few of the dates like certificate expiry are returned without milliseconds
+		dateParsed, err = time.Parse(time.RFC3339, date)
+		if err != nil {
+			return "-"
+		}
+	}
+
+	return dateParsed.Format(time.RFC822)
+}
+
+// getUserMention returns @username of the Mattermost user, falls back to user id if user isn't found
+func (p *Plugin) getUserMention(userID string) string {
+	user, appErr := p.API.GetUser(userID)
+	if appErr != nil {
+		return userID
+	}
+
+	return "@" + user.Username
+}
+
+// escapeMarkdownTableCell makes user entered text safe to be put in a markdown table cell
+func escapeMarkdownTableCell(text string) string {
+	text = strings.Replace(text, "\r\n", " ", -1)
+	text = strings.Replace(text, "\n", " ", -1)
+	return strings.Replace(text, "|", "\\|", -1)
+}
+
+// escapeCSVRow makes user entered text safe to be opened in spreadsheets, cells which would be read as a formula
+// are prefixed with a single quote so that they are shown as text.
+func escapeCSVRow(row []string) []string {
+	escapedRow := make([]string, len(row))
+	for i, cell := range row {
+		if len(cell) != 0 && strings.ContainsAny(cell[:1], "=+-@\t\r") {
+			cell = "'" + cell
+		}
+		escapedRow[i] = cell
+	}
+	return escapedRow
+}
+
+// describeSize returns bytes in readable form, in the largest unit they make at least one of
+func describeSize(bytes int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+
+	size := float64(bytes)
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%v B", bytes)
+	}
+
+	return fmt.Sprintf("%.1f %v", size, units[unit])
+}
+
 // truncateString trims the given string to specified length.
 func truncateString(s string, i int) string {
 	runes := []rune(s)
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommandFlags(t *testing.T) {
+	for name, test := range map[string]struct {
+		Command           string
+		BooleanFlags      []string
+		ExpectedArguments []string
+		ExpectedFlags     map[string]string
+	}{
+		"no flags": {
+			Command:           "blog 5f3a",
+			ExpectedArguments: []string{"blog", "5f3a"},
+			ExpectedFlags:     map[string]string{},
+		},
+		"flags after arguments": {
+			Command:           "blog --limit 5 --export csv",
+			ExpectedArguments: []string{"blog"},
+			ExpectedFlags:     map[string]string{"limit": "5", "export": "csv"},
+		},
+		"flags before arguments": {
+			Command:           "--limit 5 blog",
+			ExpectedArguments: []string{"blog"},
+			ExpectedFlags:     map[string]string{"limit": "5"},
+		},
+		"value flag between arguments": {
+			Command:           "blog --branch master 5f3a",
+			ExpectedArguments: []string{"blog", "5f3a"},
+			ExpectedFlags:     map[string]string{"branch": "master"},
+		},
+		"boolean flag before an argument": {
+			Command:           "--last-good mysite",
+			BooleanFlags:      []string{"last-good"},
+			ExpectedArguments: []string{"mysite"},
+			ExpectedFlags:     map[string]string{"last-good": "true"},
+		},
+		"boolean flag between arguments": {
+			Command:           "set blog KEY --redeploy 12",
+			BooleanFlags:      []string{"redeploy"},
+			ExpectedArguments: []string{"set", "blog", "KEY", "12"},
+			ExpectedFlags:     map[string]string{"redeploy": "true"},
+		},
+		"boolean flag along with value flags": {
+			Command:           "--public my-form --limit 5",
+			BooleanFlags:      []string{"public"},
+			ExpectedArguments: []string{"my-form"},
+			ExpectedFlags:     map[string]string{"public": "true", "limit": "5"},
+		},
+		"flag with equals": {
+			Command:           "blog --since=7d --branch=feature/a=b",
+			ExpectedArguments: []string{"blog"},
+			ExpectedFlags:     map[string]string{"since": "7d", "branch": "feature/a=b"},
+		},
+		"flag without value at the end": {
+			Command:           "blog --public",
+			ExpectedArguments: []string{"blog"},
+			ExpectedFlags:     map[string]string{"public": "true"},
+		},
+		"flag without value followed by a flag": {
+			Command:           "blog --public --limit 5",
+			ExpectedArguments: []string{"blog"},
+			ExpectedFlags:     map[string]string{"public": "true", "limit": "5"},
+		},
+		"quoted argument": {
+			Command:           `blog 5f3a known-good "passed QA on staging"`,
+			ExpectedArguments: []string{"blog", "5f3a", "known-good", "passed QA on staging"},
+			ExpectedFlags:     map[string]string{},
+		},
+		"quoted flag value": {
+			Command:           `my-team --actor "Jane Doe" --since 7d`,
+			ExpectedArguments: []string{"my-team"},
+			ExpectedFlags:     map[string]string{"actor": "Jane Doe", "since": "7d"},
+		},
+		"quoted single word": {
+			Command:           `"blog" --actor "jane"`,
+			ExpectedArguments: []string{"blog"},
+			ExpectedFlags:     map[string]string{"actor": "jane"},
+		},
+		"unterminated quote takes the rest": {
+			Command:           `blog "passed QA --limit 5`,
+			ExpectedArguments: []string{"blog", "passed QA --limit 5"},
+			ExpectedFlags:     map[string]string{},
+		},
+		"nothing": {
+			Command:       "",
+			ExpectedFlags: map[string]string{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			arguments, flags := parseCommandFlags(strings.Fields(test.Command), test.BooleanFlags...)
+			assert.Equal(t, test.ExpectedArguments, arguments)
+			assert.Equal(t, test.ExpectedFlags, flags)
+		})
+	}
+}
+
+func TestEscapeCSVRow(t *testing.T) {
+	for name, test := range map[string]struct {
+		Row      []string
+		Expected []string
+	}{
+		"plain text":        {Row: []string{"42", "Jane Doe", ""}, Expected: []string{"42", "Jane Doe", ""}},
+		"formula":           {Row: []string{"=HYPERLINK(\"http://example.com\")"}, Expected: []string{"'=HYPERLINK(\"http://example.com\")"}},
+		"plus and minus":    {Row: []string{"+1 555 0100", "-2+3"}, Expected: []string{"'+1 555 0100", "'-2+3"}},
+		"at sign":           {Row: []string{"@SUM(A1:A2)"}, Expected: []string{"'@SUM(A1:A2)"}},
+		"tab":               {Row: []string{"\t=1"}, Expected: []string{"'\t=1"}},
+		"formula not first": {Row: []string{"a=b", "jane@example.com"}, Expected: []string{"a=b", "jane@example.com"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, escapeCSVRow(test.Row))
+		})
+	}
+}
+
+func TestDescribeSize(t *testing.T) {
+	for name, test := range map[string]struct {
+		Bytes    int64
+		Expected string
+	}{
+		"nothing":   {Bytes: 0, Expected: "0 B"},
+		"bytes":     {Bytes: 512, Expected: "512 B"},
+		"kilobytes": {Bytes: 1536, Expected: "1.5 KB"},
+		"megabytes": {Bytes: 5 * 1024 * 1024, Expected: "5.0 MB"},
+		"gigabytes": {Bytes: 16106127360, Expected: "15.0 GB"},
+		"terabytes": {Bytes: 2 * 1024 * 1024 * 1024 * 1024, Expected: "2.0 TB"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, describeSize(test.Bytes))
+		})
+	}
+}
+
+func TestParseDateFlag(t *testing.T) {
+	for name, test := range map[string]struct {
+		Value         string
+		ExpectedAgo   time.Duration
+		ExpectedDate  time.Time
+		ExpectedError bool
+	}{
+		"days":           {Value: "7d", ExpectedAgo: 7 * 24 * time.Hour},
+		"hours":          {Value: "12h", ExpectedAgo: 12 * time.Hour},
+		"minutes":        {Value: "90m", ExpectedAgo: 90 * time.Minute},
+		"date":           {Value: "2020-01-31", ExpectedDate: time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)},
+		"invalid date":   {Value: "2020-13-01", ExpectedError: true},
+		"invalid days":   {Value: "xd", ExpectedError: true},
+		"unknown format": {Value: "last week", ExpectedError: true},
+		"empty":          {Value: "", ExpectedError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			date, err := parseDateFlag(test.Value)
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if test.ExpectedAgo != 0 {
+				assert.WithinDuration(t, time.Now().Add(-test.ExpectedAgo), date, time.Minute)
+				return
+			}
+			assert.Equal(t, test.ExpectedDate, date)
+		})
+	}
+}
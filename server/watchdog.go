@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	netlifyModels "github.com/netlify/open-api/go/models"
+)
+
+// CertificateWatch remembers the warnings already given for the certificate of a site, so channels are warned only once
+type CertificateWatch struct {
+	ExpiresAt        string `json:"expires_at"`
+	WarnedDaysBefore []int  `json:"warned_days_before"`
+	FailedState      string `json:"failed_state"`
+}
+
+// checkCertificatesOfSubscribedSites warns the subscribed channels of every site whose certificate is about to expire or failed to provision
+func (p *Plugin) checkCertificatesOfSubscribedSites() {
+	if p.getConfiguration().EnableCertificateWatchdog == false {
+		return
+	}
+
+	subscribedSiteIDs, connectedUserIDs, err := p.getSubscribedSitesAndConnectedUsers()
+	if err != nil {
+		p.API.LogError("Failed to get subscribed sites for checking certificates", "error", err.Error())
+		return
+	}
+
+	// Sites are looked up with the account of any connected user who can access them
+	for _, userID := range connectedUserIDs {
+		if len(subscribedSiteIDs) == 0 {
+			return
+		}
+
+		sites, err := p.listSitesOfUser(userID)
+		if err != nil {
+			continue
+		}
+
+		for _, site := range sites {
+			if subscribedSiteIDs[site.ID] == false {
+				continue
+			}
+
+			delete(subscribedSiteIDs, site.ID)
+			p.checkCertificateOfSite(userID, site)
+		}
+	}
+}
+
+func (p *Plugin) checkCertificateOfSite(userID string, site *netlifyModels.Site) {
+	certificate, err := p.getSiteTLSCertificate(userID, site.ID)
+	if err != nil || certificate == nil || len(certificate.State) == 0 {
+		// Site has no certificate to watch
+		return
+	}
+
+	certificateWatch, err := p.getCertificateWatchForSite(site.ID)
+	if err != nil {
+		p.API.LogError("Failed to get certificate warnings of site", "site_id", site.ID, "error", err.Error())
+		return
+	}
+
+	// Renewed certificate is warned about afresh
+	if certificateWatch.ExpiresAt != certificate.ExpiresAt {
+		certificateWatch.ExpiresAt = certificate.ExpiresAt
+		certificateWatch.WarnedDaysBefore = []int{}
+	}
+
+	var warning string
+
+	if certificate.State != NetlifyCertificateStateIssued && certificate.State != NetlifyCertificateStatePending {
+		if certificateWatch.FailedState != certificate.State {
+			certificateWatch.FailedState = certificate.State
+			warning = fmt.Sprintf(":rotating_light: Provisioning SSL certificate of **%v** site failed, certificate is %v for %v.\n"+
+				"Run `/netlify ssl %v` to provision it again.", site.Name, certificate.State, strings.Join(certificate.Domains, ", "), site.Name)
+		}
+	} else {
+		certificateWatch.FailedState = ""
+
+		certificateExpiresAt, err := time.Parse(NetlifyDateLayout, certificate.ExpiresAt)
+		if err != nil {
+			certificateExpiresAt, err = time.Parse(time.RFC3339, certificate.ExpiresAt)
+		}
+
+		if err == nil {
+			daysToExpiry := int(math.Ceil(time.Until(certificateExpiresAt).Hours() / 24))
+
+			// A single warning covers all the thresholds crossed since the last check
+			var isThresholdCrossed bool = false
+			for _, daysBefore := range p.getConfiguration().getCertificateExpiryWarningDays() {
+				if daysToExpiry > daysBefore || containsInt(certificateWatch.WarnedDaysBefore, daysBefore) {
+					continue
+				}
+
+				certificateWatch.WarnedDaysBefore = append(certificateWatch.WarnedDaysBefore, daysBefore)
+				isThresholdCrossed = true
+			}
+
+			if isThresholdCrossed == true {
+				warning = fmt.Sprintf(":warning: SSL certificate of **%v** site for %v expires in %v days on %v.\n"+
+					"Netlify renews it automatically as long as DNS of the domains points to Netlify, run `/netlify ssl %v` to check it.",
+					site.Name, strings.Join(certificate.Domains, ", "), daysToExpiry, certificateExpiresAt.Format(time.RFC822), site.Name)
+			}
+		}
+	}
+
+	err = p.setCertificateWatchForSite(site.ID, certificateWatch)
+	if err != nil {
+		p.API.LogError("Failed to save certificate warnings of site", "site_id", site.ID, "error", err.Error())
+		return
+	}
+
+	if len(warning) == 0 {
+		return
+	}
+
+	channelsSubscribedTo, err := p.getWebhookSubscriptionForSite(site.ID)
+	if err != nil {
+		return
+	}
+
+	for _, channelID := range channelsSubscribedTo {
+		p.sendMessageFromBot(channelID, "", false, warning)
+	}
+}
+
+// getSubscribedSitesAndConnectedUsers goes over all the keys of the KV store to find
+// sites subscribed to any channel and users who have connected their Netlify account
+func (p *Plugin) getSubscribedSitesAndConnectedUsers() (map[string]bool, []string, error) {
+	subscribedSiteIDs := map[string]bool{}
+	var connectedUserIDs []string
+
+	for page := 0; ; page++ {
+		keys, appErr := p.API.KVList(page, KVListPerPage)
+		if appErr != nil {
+			return nil, nil, appErr
+		}
+
+		for _, key := range keys {
+			if strings.HasSuffix(key, NetlifyWebhookSubscriptionsKVIdentifier) {
+				subscribedSiteIDs[strings.TrimSuffix(key, NetlifyWebhookSubscriptionsKVIdentifier)] = true
+			} else if strings.HasSuffix(key, NetlifyAuthTokenKVIdentifier) {
+				connectedUserIDs = append(connectedUserIDs, strings.TrimSuffix(key, NetlifyAuthTokenKVIdentifier))
+			}
+		}
+
+		if len(keys) < KVListPerPage {
+			break
+		}
+	}
+
+	return subscribedSiteIDs, connectedUserIDs, nil
+}
+
+// listSitesOfUser returns all the Netlify sites the user has access to.
+// Netlify library client doesn't support pagination of sites, hence calling the api directly page by page.
+func (p *Plugin) listSitesOfUser(userID string) ([]*netlifyModels.Site, error) {
+	var sites []*netlifyModels.Site
+
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(SitesPerPage))
+
+		var sitesOfPage []*netlifyModels.Site
+		err := p.sendNetlifyAPIRequest(userID, http.MethodGet, "/sites", query, nil, &sitesOfPage)
+		if err != nil {
+			return nil, err
+		}
+
+		sites = append(sites, sitesOfPage...)
+
+		if len(sitesOfPage) < SitesPerPage {
+			break
+		}
+	}
+
+	return sites, nil
+}
+
+// getCertificateExpiryWarningDays returns the days before expiry of a certificate at which channels are warned, farthest first
+func (c *configuration) getCertificateExpiryWarningDays() []int {
+	var warningDays []int
+	for _, warningDay := range strings.Split(c.CertificateExpiryWarningDays, ",") {
+		days, err := strconv.Atoi(strings.TrimSpace(warningDay))
+		if err == nil && days > 0 {
+			warningDays = append(warningDays, days)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(warningDays)))
+
+	return warningDays
+}
+
+func containsInt(slice []int, value int) bool {
+	for _, element := range slice {
+		if element == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plugin) setCertificateWatchForSite(siteID string, certificateWatch *CertificateWatch) error {
+	certificateWatchInBytes, err := json.Marshal(certificateWatch)
+	if err != nil {
+		return err
+	}
+
+	appErr := p.API.KVSet(siteID+NetlifyCertificateWatchKVIdentifier, certificateWatchInBytes)
+	if appErr != nil {
+		return appErr
+	}
+
+	return nil
+}
+
+// getCertificateWatchForSite returns the warnings given for the certificate of the site, empty if none were given
+func (p *Plugin) getCertificateWatchForSite(siteID string) (*CertificateWatch, error) {
+	certificateWatch := &CertificateWatch{}
+
+	certificateWatchInBytes, appErr := p.API.KVGet(siteID + NetlifyCertificateWatchKVIdentifier)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// It returns nil if value is not found
+	if certificateWatchInBytes == nil {
+		return certificateWatch, nil
+	}
+
+	err := json.Unmarshal(certificateWatchInBytes, certificateWatch)
+	if err != nil {
+		return nil, err
+	}
+
+	return certificateWatch, nil
+}
@@ -14,6 +14,7 @@ import (
 
 // NetlifyWebhookEvent is the struct that properties similar to what a webhook return
 type NetlifyWebhookEvent struct {
+	ID           string `json:"id"`
 	Name         string `json:"name"`
 	SiteID       string `json:"site_id"`
 	BuildID      string `json:"build_id"`
@@ -79,6 +80,25 @@ func (p *Plugin) handleWebhooks(w http.ResponseWriter, r *http.Request) {
 			Footer:    fmt.Sprintf("Using git %v branch", webhookEventData.Branch),
 		}
 
+		// Let the channel members cancel the deploy right from the notification
+		siteURL := p.API.GetConfig().ServiceSettings.SiteURL
+		if siteURL != nil && len(webhookEventData.ID) != 0 {
+			cancelButton := &model.PostAction{
+				Type: model.POST_ACTION_TYPE_BUTTON,
+				Name: "Cancel",
+				Integration: &model.PostActionIntegration{
+					URL: fmt.Sprintf("%s/plugins/netlify/command/cancel", *siteURL),
+					Context: map[string]interface{}{
+						"actionSecret": p.getConfiguration().EncryptionKey,
+						"siteID":       webhookEventData.SiteID,
+						"siteName":     webhookEventData.Name,
+						"deployID":     webhookEventData.ID,
+					},
+				},
+			}
+			messageAttachment.Actions = []*model.PostAction{cancelButton}
+		}
+
 		for _, channelID := range subscribedChannels {
 			p.API.CreatePost(&model.Post{
 				UserId:    p.BotUserID,